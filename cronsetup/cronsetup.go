@@ -12,6 +12,9 @@ import (
 	"github.com/Scalingo/go-utils/logger"
 )
 
+// logComponent is the component of the cron loggers, whose level can be overridden with LOGGER_LEVELS
+const logComponent = "cron"
+
 // Job represents a cron job. It contains 3 *mandatory* options to define a job.
 type Job = cron.Job
 
@@ -30,7 +33,8 @@ type SetupOpts struct {
 // Setup configures a new etcd cron and starts it. The caller has the responsibility to call the returned function to stop the cron jobs.
 // All errors returned by a cron job or by etcd are logged using the logger in the context.
 func Setup(ctx context.Context, opts SetupOpts) (func(), error) {
-	log := logger.Get(ctx)
+	log := logger.WithComponent(logger.Get(ctx), logComponent)
+	ctx = logger.ToCtx(ctx, log)
 
 	if opts.EtcdClient != nil && opts.EtcdConfig != nil {
 		return nil, errors.New(ctx, "both etcd client and config cannot be set")
@@ -110,24 +114,21 @@ func createEtcdMutexBuilderFromOpts(ctx context.Context, opts SetupOpts) (cron.E
 	return etcdMutexBuilder, nil
 }
 
+// funcCtx gives its own request ID to each run of a job. It is stored in the context under the "request_id" key, as
+// the middleware of httpclient does for the HTTP requests, so that it flows to the HTTP requests and NSQ messages sent by
+// the job. The context given to Setup is shared by all the runs, its request ID is only kept if the UUID of the run can't
+// be generated.
 func funcCtx(ctx context.Context, j cron.Job) context.Context {
-	log := logger.Get(ctx)
-	requestID, ok := ctx.Value("request_id").(string)
-	if !ok {
-		requestUUID, err := uuid.NewV4()
-		if err != nil {
-			log.WithError(err).Error("Error generating UUID v4")
-		} else {
-			requestID = requestUUID.String()
-			//nolint:revive,staticcheck // The "request_id" should not be of type string (https://pkg.go.dev/context#WithValue).
-			// I don't know what would be the impact of using another type for such a field that is used in various repositories. Hence I'm disabling the linters.
-			ctx = context.WithValue(ctx, "request_id", requestID)
-		}
+	fields := logrus.Fields{"job_name": j.Name}
+	requestUUID, err := uuid.NewV4()
+	if err != nil {
+		logger.Get(ctx).WithError(err).Error("Error generating UUID v4")
+	} else {
+		//nolint:revive,staticcheck // The "request_id" key is shared with other modules and repositories as a string.
+		ctx = context.WithValue(ctx, "request_id", requestUUID.String())
+		fields["request_id"] = requestUUID.String()
 	}
-	ctx, _ = logger.WithFieldsToCtx(ctx, logrus.Fields{
-		"job_name":   j.Name,
-		"request_id": requestID,
-	})
+	ctx, _ = logger.WithFieldsToCtx(ctx, fields)
 	return ctx
 }
 
@@ -0,0 +1,34 @@
+package cronsetup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Scalingo/go-utils/logger"
+)
+
+func TestFuncCtx(t *testing.T) {
+	t.Run("each run has its own request ID", func(t *testing.T) {
+		//nolint:revive,staticcheck // The "request_id" key is shared with other modules and repositories as a string.
+		ctx := context.WithValue(logger.ToCtx(context.Background(), logger.Default()), "request_id", "setup-request-id")
+		job := Job{Name: "job"}
+
+		firstCtx := funcCtx(ctx, job)
+		secondCtx := funcCtx(ctx, job)
+
+		firstRequestID, ok := firstCtx.Value("request_id").(string)
+		require.True(t, ok)
+		assert.Len(t, firstRequestID, 36)
+		assert.NotEqual(t, "setup-request-id", firstRequestID)
+		assert.NotEqual(t, firstRequestID, secondCtx.Value("request_id"))
+
+		entry, ok := logger.Get(firstCtx).(*logrus.Entry)
+		require.True(t, ok)
+		assert.Equal(t, firstRequestID, entry.Data["request_id"])
+		assert.Equal(t, "job", entry.Data["job_name"])
+	})
+}
@@ -2,10 +2,14 @@ package errors
 
 import (
 	"errors"
-
-	"gopkg.in/errgo.v1"
 )
 
+// underlyer is implemented by the errgo errors. The interface is used rather than the errgo types so that the errors
+// of the codebases migrating from errgo are handled without depending on it.
+type underlyer interface {
+	Underlying() error
+}
+
 // Is checks if any error of the stack matches the error value expectedError
 // API matching the standard library but allowing to wrap errors with ErrCtx + errgo or pkg/errors
 func Is(receivedErr, expectedError error) bool {
@@ -58,11 +62,11 @@ func UnwrapError(err error) error {
 		return u.Unwrap()
 	}
 
-	// Check if the err is type of `*errgo.Err` to be able to call `Underlying()`
+	// Check if the err is an errgo error to be able to call `Underlying()`
 	// method. Both `*errgo.Err` and `*errors.Err` are implementing a causer interface.
 	// Cause() method from errgo skip all underlying errors, so we may skip a context between.
 	// So the order matter, we need to call `Cause()` after `Underlying()`.
-	if errgoErr, ok := err.(*errgo.Err); ok {
+	if errgoErr, ok := err.(underlyer); ok {
 		return errgoErr.Underlying()
 	}
 
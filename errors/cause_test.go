@@ -213,8 +213,26 @@ func Test_UnwrapError(t *testing.T) {
 		require.ErrorAs(t, lastErr, &verr)
 	})
 
+	t.Run("given an error implementing the Underlying() interface of errgo, it returns the underlying error", func(t *testing.T) {
+		underlying := errors.New("underlying")
+
+		assert.Equal(t, underlying, UnwrapError(customErrorWithUnderlying{underlying: underlying}))
+	})
+
 	t.Run("given a nil error", func(t *testing.T) {
 		var err error
 		assert.Nil(t, UnwrapError(err))
 	})
 }
+
+type customErrorWithUnderlying struct {
+	underlying error
+}
+
+func (err customErrorWithUnderlying) Error() string {
+	return "custom: " + err.underlying.Error()
+}
+
+func (err customErrorWithUnderlying) Underlying() error {
+	return err.underlying
+}
@@ -0,0 +1,20 @@
+// Command errorslint runs the analyzers of the errors module. It can be run alone or by go vet:
+//
+//	go install github.com/Scalingo/go-utils/errors/v3/cmd/errorslint@latest
+//	go vet -vettool=$(which errorslint) ./...
+//	errorslint -fix ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/multichecker"
+
+	"github.com/Scalingo/go-utils/errors/v3/lint/ctxwrap"
+	"github.com/Scalingo/go-utils/errors/v3/lint/errgomigration"
+)
+
+func main() {
+	multichecker.Main(
+		ctxwrap.Analyzer,
+		errgomigration.Analyzer,
+	)
+}
@@ -2,14 +2,15 @@ package errors
 
 import (
 	"context"
+	"runtime"
 
 	"github.com/pkg/errors"
-	"gopkg.in/errgo.v1"
 )
 
 type ErrCtx struct {
-	ctx context.Context
-	err error
+	ctx   context.Context
+	err   error
+	stack *stack
 }
 
 func (err ErrCtx) Error() string {
@@ -25,24 +26,30 @@ func (err ErrCtx) Unwrap() error {
 	return err.err
 }
 
+// StackTrace returns the stack trace captured when the error or one of the errors it wraps has been created. It is
+// nil if the capture is disabled (see EnableStackTrace).
+func (err ErrCtx) StackTrace() []runtime.Frame {
+	return StackTrace(err)
+}
+
 func New(ctx context.Context, message string) error {
-	return ErrCtx{ctx: ctx, err: errors.New(message)}
+	return ErrCtx{ctx: ctx, err: errors.New(message), stack: callers()}
 }
 
 func Newf(ctx context.Context, format string, args ...interface{}) error {
-	return Errorf(ctx, format, args...)
+	return ErrCtx{ctx: ctx, err: errors.Errorf(format, args...), stack: callers()}
 }
 
 func Wrap(ctx context.Context, err error, message string) error {
-	return ErrCtx{ctx: ctx, err: errors.Wrap(err, message)}
+	return ErrCtx{ctx: ctx, err: errors.Wrap(err, message), stack: wrappingCallers(err)}
 }
 
 func Wrapf(ctx context.Context, err error, format string, args ...interface{}) error {
-	return ErrCtx{ctx: ctx, err: errors.Wrapf(err, format, args...)}
+	return ErrCtx{ctx: ctx, err: errors.Wrapf(err, format, args...), stack: wrappingCallers(err)}
 }
 
 func Errorf(ctx context.Context, format string, args ...interface{}) error {
-	return ErrCtx{ctx: ctx, err: errors.Errorf(format, args...)}
+	return ErrCtx{ctx: ctx, err: errors.Errorf(format, args...), stack: callers()}
 }
 
 // RootCtxOrFallback unwrap all wrapped errors from err to get the deepest context
@@ -52,11 +59,11 @@ func RootCtxOrFallback(ctx context.Context, err error) context.Context {
 
 	// Unwrap each error to get the deepest context
 	for err != nil {
-		// First check if the err is type of `*errgo.Err` to be able to call `Underlying()`
+		// First check if the err is an errgo error to be able to call `Underlying()`
 		// method. Both `*errgo.Err` and `*errors.Err` are implementing a causer interface.
 		// Cause() method from errgo skip all underlying errors, so we may skip a context between.
 		// So the order matter, we need to call `Cause()` after `Underlying()`.
-		errgoErr, ok := err.(*errgo.Err)
+		errgoErr, ok := err.(underlyer)
 		if ok {
 			err = errgoErr.Underlying()
 			continue
@@ -89,6 +96,13 @@ func RootCtxOrFallback(ctx context.Context, err error) context.Context {
 			continue
 		}
 
+		// Other wrapping errors, e.g. the ones created by WithKind
+		unwrapper, ok := err.(interface{ Unwrap() error })
+		if ok {
+			err = unwrapper.Unwrap()
+			continue
+		}
+
 		break
 	}
 
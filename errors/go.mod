@@ -1,15 +1,18 @@
 module github.com/Scalingo/go-utils/errors/v3
 
-go 1.24
+go 1.24.0
 
 require (
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.11.1
+	golang.org/x/tools v0.42.0
 	gopkg.in/errgo.v1 v1.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/mod v0.33.0 // indirect
+	golang.org/x/sync v0.19.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,164 @@
+package errors
+
+import (
+	"net/http"
+)
+
+// Kind is the category of an error, used to choose the status code returned to the clients
+type Kind string
+
+const (
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindInvalid      Kind = "invalid"
+	KindUnavailable  Kind = "unavailable"
+	KindTimeout      Kind = "timeout"
+	KindInternal     Kind = "internal"
+)
+
+// gRPC status codes, see google.golang.org/grpc/codes
+const (
+	grpcCodeInvalidArgument  uint32 = 3
+	grpcCodeDeadlineExceeded uint32 = 4
+	grpcCodeNotFound         uint32 = 5
+	grpcCodeAlreadyExists    uint32 = 6
+	grpcCodePermissionDenied uint32 = 7
+	grpcCodeInternal         uint32 = 13
+	grpcCodeUnavailable      uint32 = 14
+	grpcCodeUnauthenticated  uint32 = 16
+)
+
+type kindError struct {
+	err  error
+	kind Kind
+}
+
+func (err kindError) Error() string {
+	return err.err.Error()
+}
+
+func (err kindError) Unwrap() error {
+	return err.err
+}
+
+func (err kindError) Kind() Kind {
+	return err.kind
+}
+
+// WithKind attaches a kind to the error. The error can then be wrapped: KindOf returns the kind attached the closest
+// to the top of the chain.
+//
+//	return errors.Wrap(ctx, errors.WithKind(err, errors.KindConflict), "create app")
+func WithKind(err error, kind Kind) error {
+	if err == nil {
+		return nil
+	}
+	return kindError{err: err, kind: kind}
+}
+
+// KindOf walks the chain of wrapped errors and returns the first kind found. Besides the kinds attached with WithKind,
+// it recognizes:
+//   - *ValidationErrors as KindInvalid
+//   - the errors with a `NotFound() bool` method returning true (e.g. storage.ObjectNotFound) as KindNotFound
+//   - the errors with a `Timeout() bool` method returning true (e.g. context.DeadlineExceeded) as KindTimeout
+//
+// The errors wrapping several errors with an `Unwrap() []error` method (e.g. Join or MultiError) are walked depth-first:
+// the kind of the first of their errors having one is returned, the kind attached to the joining error itself taking
+// precedence.
+//
+// It returns KindInternal if no kind is found and an empty Kind if err is nil.
+func KindOf(err error) Kind {
+	if err == nil {
+		return ""
+	}
+
+	kind, ok := findKind(err)
+	if !ok {
+		return KindInternal
+	}
+	return kind
+}
+
+func findKind(err error) (Kind, bool) {
+	for unwrappedErr := err; unwrappedErr != nil; unwrappedErr = UnwrapError(unwrappedErr) {
+		switch e := unwrappedErr.(type) {
+		case interface{ Kind() Kind }:
+			return e.Kind(), true
+		case *ValidationErrors:
+			return KindInvalid, true
+		case interface{ NotFound() bool }:
+			if e.NotFound() {
+				return KindNotFound, true
+			}
+		case interface{ Timeout() bool }:
+			if e.Timeout() {
+				return KindTimeout, true
+			}
+		case interface{ Unwrap() []error }:
+			for _, joinedErr := range e.Unwrap() {
+				kind, ok := findKind(joinedErr)
+				if ok {
+					return kind, true
+				}
+			}
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// HTTPStatus returns the HTTP status code matching the kind. The unknown kinds are internal errors.
+func (k Kind) HTTPStatus() int {
+	switch k {
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindInvalid:
+		return http.StatusUnprocessableEntity
+	case KindUnavailable:
+		return http.StatusServiceUnavailable
+	case KindTimeout:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GRPCCode returns the gRPC status code matching the kind, as a value of google.golang.org/grpc/codes which is not a
+// dependency of this package:
+//
+//	status.Error(codes.Code(errors.KindOf(err).GRPCCode()), err.Error())
+//
+// The unknown kinds are internal errors.
+func (k Kind) GRPCCode() uint32 {
+	switch k {
+	case KindNotFound:
+		return grpcCodeNotFound
+	case KindConflict:
+		return grpcCodeAlreadyExists
+	case KindUnauthorized:
+		return grpcCodeUnauthenticated
+	case KindForbidden:
+		return grpcCodePermissionDenied
+	case KindInvalid:
+		return grpcCodeInvalidArgument
+	case KindUnavailable:
+		return grpcCodeUnavailable
+	case KindTimeout:
+		return grpcCodeDeadlineExceeded
+	default:
+		return grpcCodeInternal
+	}
+}
+
+// HTTPStatus returns the HTTP status code matching the kind of the error (see KindOf)
+func HTTPStatus(err error) int {
+	return KindOf(err).HTTPStatus()
+}
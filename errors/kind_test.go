@@ -0,0 +1,156 @@
+// Ignore context.WithValue check
+//
+//nolint:revive,staticcheck
+package errors
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/errgo.v1"
+)
+
+type notFoundError struct{}
+
+func (err notFoundError) Error() string {
+	return "object not found"
+}
+
+func (err notFoundError) NotFound() bool {
+	return true
+}
+
+func TestKindOf(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		err          error
+		expectedKind Kind
+	}{
+		"a nil error": {
+			err:          nil,
+			expectedKind: "",
+		},
+		"an error without kind": {
+			err:          stdErrors.New("connection refused"),
+			expectedKind: KindInternal,
+		},
+		"an error with a kind": {
+			err:          WithKind(stdErrors.New("app already exists"), KindConflict),
+			expectedKind: KindConflict,
+		},
+		"a wrapped error with a kind": {
+			err:          Wrap(ctx, errgo.Notef(WithKind(New(ctx, "app not found"), KindNotFound), "fetch app"), "deploy app"),
+			expectedKind: KindNotFound,
+		},
+		"the kind closest to the top of the chain": {
+			err:          WithKind(Wrap(ctx, WithKind(stdErrors.New("app not found"), KindNotFound), "fetch app"), KindForbidden),
+			expectedKind: KindForbidden,
+		},
+		"validation errors": {
+			err:          Wrap(ctx, NewValidationErrorsBuilder().Set("name", "is empty").Build(), "validate app"),
+			expectedKind: KindInvalid,
+		},
+		"an error with a NotFound method": {
+			err:          Wrap(ctx, notFoundError{}, "get object"),
+			expectedKind: KindNotFound,
+		},
+		"an error with a Timeout method": {
+			err:          fmt.Errorf("fetch app: %w", context.DeadlineExceeded),
+			expectedKind: KindTimeout,
+		},
+		"the first kind of the joined errors": {
+			err:          Wrap(ctx, Join(stdErrors.New("connection refused"), notFoundError{}, WithKind(stdErrors.New("locked"), KindConflict)), "fetch apps"),
+			expectedKind: KindNotFound,
+		},
+		"the kind of the joining error": {
+			err:          WithKind(Join(notFoundError{}), KindForbidden),
+			expectedKind: KindForbidden,
+		},
+		"joined errors without kind": {
+			err:          fmt.Errorf("fetch apps: %w, %w", stdErrors.New("connection refused"), stdErrors.New("connection reset")),
+			expectedKind: KindInternal,
+		},
+		"the errors of a multi error": {
+			err:          multiErrorOf(ctx, stdErrors.New("connection refused"), WithKind(stdErrors.New("locked"), KindConflict)),
+			expectedKind: KindConflict,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expectedKind, KindOf(test.err))
+		})
+	}
+}
+
+func multiErrorOf(ctx context.Context, errs ...error) error {
+	multiErr := NewMultiError(len(errs))
+	for i, err := range errs {
+		multiErr.Add(ctx, fmt.Sprint(i), err)
+	}
+	return multiErr.ErrorOrNil()
+}
+
+func TestKind_HTTPStatus(t *testing.T) {
+	tests := map[Kind]int{
+		KindNotFound:     http.StatusNotFound,
+		KindConflict:     http.StatusConflict,
+		KindUnauthorized: http.StatusUnauthorized,
+		KindForbidden:    http.StatusForbidden,
+		KindInvalid:      http.StatusUnprocessableEntity,
+		KindUnavailable:  http.StatusServiceUnavailable,
+		KindTimeout:      http.StatusGatewayTimeout,
+		KindInternal:     http.StatusInternalServerError,
+		Kind("unknown"):  http.StatusInternalServerError,
+	}
+
+	for kind, expectedStatus := range tests {
+		t.Run(string(kind), func(t *testing.T) {
+			assert.Equal(t, expectedStatus, kind.HTTPStatus())
+		})
+	}
+
+	assert.Equal(t, http.StatusNotFound, HTTPStatus(WithKind(stdErrors.New("app not found"), KindNotFound)))
+}
+
+func TestKind_GRPCCode(t *testing.T) {
+	tests := map[Kind]uint32{
+		KindNotFound:     5,
+		KindConflict:     6,
+		KindUnauthorized: 16,
+		KindForbidden:    7,
+		KindInvalid:      3,
+		KindUnavailable:  14,
+		KindTimeout:      4,
+		KindInternal:     13,
+		Kind("unknown"):  13,
+	}
+
+	for kind, expectedCode := range tests {
+		t.Run(string(kind), func(t *testing.T) {
+			assert.Equal(t, expectedCode, kind.GRPCCode())
+		})
+	}
+}
+
+func TestWithKind(t *testing.T) {
+	assert.NoError(t, WithKind(nil, KindNotFound))
+
+	err := stdErrors.New("app not found")
+	kindErr := WithKind(err, KindNotFound)
+	assert.Equal(t, "app not found", kindErr.Error())
+	assert.True(t, Is(kindErr, err))
+}
+
+func TestWithKind_RootCtxOrFallback(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "field", "value")
+
+	err := WithKind(New(ctx, "app not found"), KindNotFound)
+
+	assert.Equal(t, "value", RootCtxOrFallback(context.Background(), err).Value("field"))
+}
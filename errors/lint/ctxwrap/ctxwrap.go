@@ -0,0 +1,294 @@
+// Package ctxwrap defines an analyzer enforcing the wrapping of the errors with errors.Wrap(ctx, ...) of
+// github.com/Scalingo/go-utils/errors/v3, which keeps the logger context of the ErrCtx. It reports:
+//
+//   - the errors formatted with %v or %s by fmt.Errorf, which drops the error chain
+//   - the errors wrapped by fmt.Errorf or github.com/pkg/errors when a context.Context is in scope
+//   - the comparisons of errors with == and != against sentinel errors instead of errors.Is
+package ctxwrap
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/Scalingo/go-utils/errors/v3/lint/internal/analysisutil"
+)
+
+const pkgErrorsPath = "github.com/pkg/errors"
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "ctxwrap",
+	Doc:      "report the errors wrapped without errors.Wrap(ctx, ...) and the comparisons against sentinel errors with ==",
+	URL:      "https://pkg.go.dev/github.com/Scalingo/go-utils/errors/v3/lint/ctxwrap",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var errorType = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+// pkgErrorsWrappers are the functions of github.com/pkg/errors wrapping an error
+var pkgErrorsWrappers = map[string]bool{
+	"Wrap":         true,
+	"Wrapf":        true,
+	"WithMessage":  true,
+	"WithMessagef": true,
+	"WithStack":    true,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	for _, file := range pass.Files {
+		fileCursor, _ := inspect.Root().FindNode(file)
+		for cursor := range fileCursor.Preorder((*ast.CallExpr)(nil), (*ast.BinaryExpr)(nil)) {
+			switch node := cursor.Node().(type) {
+			case *ast.CallExpr:
+				// The errors package implements the wrapping with the context
+				if pass.Pkg.Path() != analysisutil.ErrorsPath {
+					checkCall(pass, file, cursor)
+				}
+			case *ast.BinaryExpr:
+				if !inIsMethod(cursor) {
+					checkComparison(pass, file, node)
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+func checkCall(pass *analysis.Pass, file *ast.File, cursor inspector.Cursor) {
+	call := cursor.Node().(*ast.CallExpr)
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	function, ok := pass.TypesInfo.Uses[selector.Sel].(*types.Func)
+	if !ok || function.Pkg() == nil {
+		return
+	}
+
+	switch {
+	case function.Pkg().Path() == "fmt" && function.Name() == "Errorf":
+		checkErrorf(pass, file, cursor)
+	case function.Pkg().Path() == pkgErrorsPath && pkgErrorsWrappers[function.Name()]:
+		if analysisutil.ContextInScope(pass, call.Pos()) == "" {
+			return
+		}
+		pass.Reportf(call.Pos(), "%s.%s wraps the error without the context in scope, use errors.Wrap(ctx, ...) of %s",
+			pkgErrorsPath, function.Name(), analysisutil.ErrorsPath)
+	}
+}
+
+// verb is a formatting verb of a format string and the index of its argument
+type verb struct {
+	verb  rune
+	arg   int
+	start int
+}
+
+func checkErrorf(pass *analysis.Pass, file *ast.File, cursor inspector.Cursor) {
+	call := cursor.Node().(*ast.CallExpr)
+	if len(call.Args) == 0 || call.Ellipsis.IsValid() {
+		return
+	}
+	format := pass.TypesInfo.Types[call.Args[0]].Value
+	if format == nil || format.Kind() != constant.String {
+		return
+	}
+	verbs, ok := parseFormat(constant.StringVal(format))
+	if !ok {
+		return
+	}
+
+	var errorVerb *verb
+	for i, v := range verbs {
+		if v.arg+1 >= len(call.Args) || !isError(pass, call.Args[v.arg+1]) {
+			continue
+		}
+		if v.verb == 'v' || v.verb == 's' {
+			errorVerb = &verbs[i]
+			break
+		}
+		if v.verb == 'w' && errorVerb == nil {
+			errorVerb = &verbs[i]
+		}
+	}
+	if errorVerb == nil {
+		return
+	}
+
+	ctx := analysisutil.ContextInScope(pass, call.Pos())
+	var message string
+	switch {
+	case errorVerb.verb != 'w':
+		message = fmt.Sprintf("fmt.Errorf formats the error with %%%c which drops the error chain, use errors.Wrap(ctx, ...) of %s or %%w", errorVerb.verb, analysisutil.ErrorsPath)
+	case ctx != "":
+		message = fmt.Sprintf("fmt.Errorf wraps the error without the context in scope, use errors.Wrap(ctx, ...) of %s", analysisutil.ErrorsPath)
+	default:
+		return
+	}
+
+	diagnostic := analysis.Diagnostic{Pos: call.Pos(), End: call.End(), Message: message}
+	if fix := wrapReplacement(pass, file, cursor, verbs, *errorVerb, ctx); fix != "" {
+		diagnostic.SuggestedFixes = []analysis.SuggestedFix{{
+			Message:   "Replace with " + strings.SplitN(fix, "(", 2)[0],
+			TextEdits: append([]analysis.TextEdit{{Pos: call.Pos(), End: call.End(), NewText: []byte(fix)}}, analysisutil.AddErrorsImport(file)...),
+		}}
+	}
+	pass.Report(diagnostic)
+}
+
+// wrapReplacement returns the errors.Wrap call replacing fmt.Errorf("message: %v", ..., err), or "" if the call can't
+// be rewritten mechanically. As errors.Wrap must not wrap a nil error, the error must be known to be non-nil (see
+// analysisutil.IsNonNil).
+func wrapReplacement(pass *analysis.Pass, file *ast.File, cursor inspector.Cursor, verbs []verb, errorVerb verb, ctx string) string {
+	call := cursor.Node().(*ast.CallExpr)
+	errors := analysisutil.ErrorsPackageName(pass, file, call.Pos())
+	if ctx == "" || errors == "" || !analysisutil.IsNonNil(pass, cursor, call.Args[len(call.Args)-1]) {
+		return ""
+	}
+	// The error must be the last argument, formatted at the end of the message after ": "
+	format := constant.StringVal(pass.TypesInfo.Types[call.Args[0]].Value)
+	if verbs[len(verbs)-1] != errorVerb || errorVerb.arg != len(call.Args)-2 || !strings.HasSuffix(format[:errorVerb.start], ": ") ||
+		format[errorVerb.start:] != "%"+string(errorVerb.verb) {
+		return ""
+	}
+
+	message := strings.TrimSuffix(format[:errorVerb.start], ": ")
+	err := analysisutil.Source(pass, call.Args[len(call.Args)-1])
+	if len(verbs) == 1 {
+		// The message isn't a format anymore
+		return fmt.Sprintf("%s.Wrap(%s, %s, %s)", errors, ctx, err, strconv.Quote(strings.ReplaceAll(message, "%%", "%")))
+	}
+	args := []string{strconv.Quote(message)}
+	for _, arg := range call.Args[1 : len(call.Args)-1] {
+		args = append(args, analysisutil.Source(pass, arg))
+	}
+	return fmt.Sprintf("%s.Wrapf(%s, %s, %s)", errors, ctx, err, strings.Join(args, ", "))
+}
+
+// parseFormat returns the verbs of a format string. It returns false if the format uses explicit argument indexes.
+func parseFormat(format string) ([]verb, bool) {
+	var verbs []verb
+	arg := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		start := i
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+		for i < len(format) && (format[i] >= '0' && format[i] <= '9' || format[i] == '.' || format[i] == '*') {
+			// The * width and precision consume an argument
+			if format[i] == '*' {
+				arg++
+			}
+			i++
+		}
+		if i >= len(format) {
+			break
+		}
+		switch format[i] {
+		case '%':
+			continue
+		case '[':
+			return nil, false
+		}
+		verbs = append(verbs, verb{verb: rune(format[i]), arg: arg, start: start})
+		arg++
+	}
+	return verbs, true
+}
+
+func checkComparison(pass *analysis.Pass, file *ast.File, expr *ast.BinaryExpr) {
+	if expr.Op != token.EQL && expr.Op != token.NEQ {
+		return
+	}
+	value, sentinel := expr.X, expr.Y
+	if !isSentinel(pass, sentinel) {
+		value, sentinel = sentinel, value
+	}
+	if !isSentinel(pass, sentinel) || !isError(pass, value) || isSentinel(pass, value) {
+		return
+	}
+
+	replacement := fmt.Sprintf("Is(%s, %s)", analysisutil.Source(pass, value), analysisutil.Source(pass, sentinel))
+	if expr.Op == token.NEQ {
+		replacement = "!" + replacement
+	}
+	diagnostic := analysis.Diagnostic{
+		Pos:     expr.Pos(),
+		End:     expr.End(),
+		Message: fmt.Sprintf("comparison with %s against a sentinel error, use errors.%s", expr.Op, strings.TrimPrefix(replacement, "!")),
+	}
+
+	// The errors package of the standard library is used if it's already imported
+	var edits []analysis.TextEdit
+	errors := analysisutil.ImportName(file, "errors")
+	if errors != "" {
+		if _, object := pass.Pkg.Scope().Innermost(expr.Pos()).LookupParent(errors, expr.Pos()); object == nil {
+			errors = ""
+		}
+	}
+	if errors == "" {
+		errors = analysisutil.ErrorsPackageName(pass, file, expr.Pos())
+		edits = analysisutil.AddErrorsImport(file)
+	}
+	if errors != "" {
+		fix := strings.Replace(replacement, "Is(", errors+".Is(", 1)
+		diagnostic.SuggestedFixes = []analysis.SuggestedFix{{
+			Message:   "Replace with " + errors + ".Is",
+			TextEdits: append([]analysis.TextEdit{{Pos: expr.Pos(), End: expr.End(), NewText: []byte(fix)}}, edits...),
+		}}
+	}
+	pass.Report(diagnostic)
+}
+
+// isSentinel returns whether expr is a package level variable of an error type, e.g. io.EOF
+func isSentinel(pass *analysis.Pass, expr ast.Expr) bool {
+	var ident *ast.Ident
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		ident = e
+	case *ast.SelectorExpr:
+		ident = e.Sel
+	default:
+		return false
+	}
+	variable, ok := pass.TypesInfo.Uses[ident].(*types.Var)
+	if !ok || variable.Pkg() == nil || variable.Parent() != variable.Pkg().Scope() {
+		return false
+	}
+	return types.Implements(variable.Type(), errorType)
+}
+
+func isError(pass *analysis.Pass, expr ast.Expr) bool {
+	t := pass.TypesInfo.TypeOf(expr)
+	if t == nil {
+		return false
+	}
+	if basic, ok := t.(*types.Basic); ok && basic.Kind() == types.UntypedNil {
+		return false
+	}
+	return types.Implements(t, errorType)
+}
+
+// inIsMethod returns whether the cursor is in an Is method, which implements the comparison used by errors.Is
+func inIsMethod(cursor inspector.Cursor) bool {
+	for enclosing := range cursor.Enclosing((*ast.FuncDecl)(nil)) {
+		funcDecl := enclosing.Node().(*ast.FuncDecl)
+		return funcDecl.Recv != nil && funcDecl.Name.Name == "Is"
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package a
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+var ErrNotFound = fmt.Errorf("not found")
+
+type customError struct{}
+
+func (customError) Error() string { return "custom" }
+
+func (customError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+func getApp(ctx context.Context, name string) error {
+	err := fetch(name)
+	if err == ErrNotFound { // want `comparison with == against a sentinel error, use errors.Is\(err, ErrNotFound\)`
+		return fmt.Errorf("get app %s: %v", name, err) // want `fmt.Errorf formats the error with %v`
+	}
+	if io.EOF != err { // want `comparison with != against a sentinel error, use errors.Is\(err, io.EOF\)`
+		// err may be nil, it isn't wrapped with errors.Wrap
+		return fmt.Errorf("get app: %w", err) // want `fmt.Errorf wraps the error without the context in scope`
+	}
+	if err != nil {
+		return pkgerrors.Wrap(err, "get app") // want `github.com/pkg/errors.Wrap wraps the error without the context in scope`
+	}
+	return fmt.Errorf("100%% of %s: %+v", name, err) // want `fmt.Errorf formats the error with %v`
+}
+
+func withoutContext(name string) error {
+	err := fetch(name)
+	if err != nil {
+		return fmt.Errorf("fetch: %w", err)
+	}
+	if err == nil || ErrNotFound == io.EOF {
+		return pkgerrors.Wrap(err, "fetch")
+	}
+	return fmt.Errorf("fetch %s: %s", name, err) // want `fmt.Errorf formats the error with %s`
+}
+
+func fetch(name string) error {
+	return nil
+}
@@ -0,0 +1,12 @@
+package b
+
+import (
+	"errors"
+	"io"
+)
+
+func read(err error) bool {
+	return err == io.EOF // want `comparison with == against a sentinel error, use errors.Is\(err, io.EOF\)`
+}
+
+var errClosed = errors.New("closed")
@@ -0,0 +1,9 @@
+package errors
+
+import "context"
+
+func Wrap(ctx context.Context, err error, message string) error { return err }
+
+func Wrapf(ctx context.Context, err error, format string, args ...interface{}) error { return err }
+
+func Is(err, target error) bool { return false }
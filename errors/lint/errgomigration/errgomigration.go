@@ -0,0 +1,164 @@
+// Package errgomigration defines an analyzer reporting the calls to errgo.Mask and errgo.Notef, with suggested fixes
+// rewriting them to errors.Wrap and errors.Wrapf of github.com/Scalingo/go-utils/errors/v3 with the context in scope.
+package errgomigration
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/Scalingo/go-utils/errors/v3/lint/internal/analysisutil"
+)
+
+const (
+	errgoPath = "gopkg.in/errgo.v1"
+	// maskMessage is the message of the errors.Wrap replacing errgo.Mask, which has none. It must be replaced by the
+	// developer.
+	maskMessage = "TODO: describe the failed operation"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "errgomigration",
+	Doc:      "report the errgo.Mask and errgo.Notef calls and suggest to replace them with errors.Wrap(ctx, ...)",
+	URL:      "https://pkg.go.dev/github.com/Scalingo/go-utils/errors/v3/lint/errgomigration",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// migration is an errgo call to report in a file
+type migration struct {
+	call     *ast.CallExpr
+	function string
+	fix      string
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	for _, file := range pass.Files {
+		var migrations []migration
+		fileCursor, _ := inspect.Root().FindNode(file)
+		for cursor := range fileCursor.Preorder((*ast.CallExpr)(nil)) {
+			call := cursor.Node().(*ast.CallExpr)
+			function := calledErrgoFunction(pass, call)
+			if function != "Mask" && function != "Notef" {
+				continue
+			}
+			migrations = append(migrations, migration{
+				call:     call,
+				function: function,
+				fix:      replacement(pass, file, cursor, function),
+			})
+		}
+
+		fixable := 0
+		for _, m := range migrations {
+			if m.fix != "" {
+				fixable++
+			}
+		}
+		// The import of errgo is removed if all its uses are fixed
+		removeErrgo := fixable == analysisutil.PackageUses(pass, file, errgoPath)
+		for _, m := range migrations {
+			diagnostic := analysis.Diagnostic{
+				Pos:     m.call.Pos(),
+				End:     m.call.End(),
+				Message: fmt.Sprintf("errgo.%s is deprecated, wrap the error with errors.Wrap(ctx, ...) of %s", m.function, analysisutil.ErrorsPath),
+			}
+			if m.fix != "" {
+				edits := []analysis.TextEdit{{Pos: m.call.Pos(), End: m.call.End(), NewText: []byte(m.fix)}}
+				edits = append(edits, analysisutil.AddErrorsImport(file)...)
+				if removeErrgo {
+					edits = append(edits, analysisutil.RemoveImport(file, errgoPath)...)
+				}
+				diagnostic.SuggestedFixes = []analysis.SuggestedFix{{
+					Message:   "Replace with " + strings.SplitN(m.fix, "(", 2)[0],
+					TextEdits: edits,
+				}}
+			}
+			pass.Report(diagnostic)
+		}
+	}
+	return nil, nil
+}
+
+// calledErrgoFunction returns the name of the errgo function called, or "" if the call is not an errgo call
+func calledErrgoFunction(pass *analysis.Pass, call *ast.CallExpr) string {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	function, ok := pass.TypesInfo.Uses[selector.Sel].(*types.Func)
+	if !ok || function.Pkg() == nil || function.Pkg().Path() != errgoPath {
+		return ""
+	}
+	return function.Name()
+}
+
+// replacement returns the code replacing the errgo call, or "" if the call can't be rewritten mechanically. As
+// errors.Wrap must not wrap a nil error, the wrapped error must be known to be non-nil (see analysisutil.IsNonNil).
+func replacement(pass *analysis.Pass, file *ast.File, cursor inspector.Cursor, function string) string {
+	call := cursor.Node().(*ast.CallExpr)
+	ctx := analysisutil.ContextInScope(pass, call.Pos())
+	errors := analysisutil.ErrorsPackageName(pass, file, call.Pos())
+	if ctx == "" || errors == "" || len(call.Args) == 0 || call.Ellipsis.IsValid() {
+		return ""
+	}
+
+	args := make([]string, 0, len(call.Args))
+	for _, arg := range call.Args {
+		args = append(args, analysisutil.Source(pass, arg))
+	}
+
+	switch function {
+	case "Mask":
+		// The predicates select the causes kept by errgo, which has no equivalent
+		if len(args) > 1 || !analysisutil.IsNonNil(pass, cursor, call.Args[0]) {
+			return ""
+		}
+		return fmt.Sprintf("%s.Wrap(%s, %s, %s)", errors, ctx, args[0], strconv.Quote(maskMessage))
+	case "Notef":
+		if len(args) < 2 {
+			return ""
+		}
+		if len(args) == 2 {
+			// The format isn't formatted anymore, its %% are unescaped
+			message := constantMessage(pass, call.Args[1])
+			if message == "" {
+				return ""
+			}
+			// errgo.Notef(nil, ...) creates a new error
+			if analysisutil.IsNil(pass, call.Args[0]) {
+				return fmt.Sprintf("%s.New(%s, %s)", errors, ctx, message)
+			}
+			if !analysisutil.IsNonNil(pass, cursor, call.Args[0]) {
+				return ""
+			}
+			return fmt.Sprintf("%s.Wrap(%s, %s, %s)", errors, ctx, args[0], message)
+		}
+		if analysisutil.IsNil(pass, call.Args[0]) {
+			return fmt.Sprintf("%s.Newf(%s, %s)", errors, ctx, strings.Join(args[1:], ", "))
+		}
+		if !analysisutil.IsNonNil(pass, cursor, call.Args[0]) {
+			return ""
+		}
+		return fmt.Sprintf("%s.Wrapf(%s, %s, %s)", errors, ctx, args[0], strings.Join(args[1:], ", "))
+	}
+	return ""
+}
+
+// constantMessage returns the quoted message of a format without arguments, or "" if it isn't a constant
+func constantMessage(pass *analysis.Pass, format ast.Expr) string {
+	value := pass.TypesInfo.Types[format].Value
+	if value == nil || value.Kind() != constant.String {
+		return ""
+	}
+	return strconv.Quote(strings.ReplaceAll(constant.StringVal(value), "%%", "%"))
+}
@@ -0,0 +1,37 @@
+package a
+
+import (
+	"context"
+
+	"gopkg.in/errgo.v1"
+)
+
+func createApp(ctx context.Context, name string) error {
+	err := save(name)
+	if err != nil {
+		return errgo.Mask(err) // want `errgo.Mask is deprecated`
+	}
+	err = save(name)
+	if err != nil {
+		return errgo.Notef(err, "fail to save app") // want `errgo.Notef is deprecated`
+	}
+	if err := save(name); err != nil && name != "" {
+		return errgo.Notef(err, "fail to save app again") // want `errgo.Notef is deprecated`
+	}
+	if name == "" {
+		return errgo.Notef(nil, "invalid name %s", name) // want `errgo.Notef is deprecated`
+	}
+	if name == "%" {
+		return errgo.Notef(nil, "invalid name 100%%") // want `errgo.Notef is deprecated`
+	}
+	err = save(name)
+	if err != nil {
+		return errgo.Notef(err, name) // want `errgo.Notef is deprecated`
+	}
+	// err may be nil, it isn't wrapped with errors.Wrapf
+	return errgo.Notef(err, "fail to save app %s", name) // want `errgo.Notef is deprecated`
+}
+
+func save(name string) error {
+	return nil
+}
@@ -0,0 +1,27 @@
+package b
+
+import (
+	"context"
+	"errors"
+
+	scerrors "github.com/Scalingo/go-utils/errors/v3"
+	"gopkg.in/errgo.v1"
+)
+
+var errNotFound = errors.New("not found")
+
+func getApp(appCtx context.Context) error {
+	return errgo.Notef(errNotFound, "get app") // want `errgo.Notef is deprecated`
+}
+
+func withoutContext() error {
+	return errgo.Notef(errNotFound, "no context") // want `errgo.Notef is deprecated`
+}
+
+func withPredicates(ctx context.Context) error {
+	return errgo.Mask(errNotFound, errgo.Any) // want `errgo.Mask is deprecated`
+}
+
+func wrap(ctx context.Context) error {
+	return scerrors.Wrap(ctx, errNotFound, "wrap")
+}
@@ -0,0 +1,11 @@
+package errors
+
+import "context"
+
+func New(ctx context.Context, message string) error { return nil }
+
+func Newf(ctx context.Context, format string, args ...interface{}) error { return nil }
+
+func Wrap(ctx context.Context, err error, message string) error { return err }
+
+func Wrapf(ctx context.Context, err error, format string, args ...interface{}) error { return err }
@@ -0,0 +1,8 @@
+package errgo
+
+func New(s string) error { return nil }
+
+func Mask(underlying error, pass ...func(error) bool) error { return underlying }
+
+func Notef(underlying error, f string, a ...interface{}) error { return underlying }
+func Any(error) bool                                           { return true }
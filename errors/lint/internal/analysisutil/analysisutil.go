@@ -0,0 +1,241 @@
+// Package analysisutil contains the helpers shared by the analyzers of the errors module
+package analysisutil
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// ErrorsPath is the import path of the errors package suggested by the analyzers
+const ErrorsPath = "github.com/Scalingo/go-utils/errors/v3"
+
+// ContextInScope returns the name of a context.Context variable in scope at pos, preferably "ctx", or ""
+func ContextInScope(pass *analysis.Pass, pos token.Pos) string {
+	innermost := pass.Pkg.Scope().Innermost(pos)
+	found := ""
+	for scope := innermost; scope != nil && scope != pass.Pkg.Scope(); scope = scope.Parent() {
+		for _, name := range scope.Names() {
+			variable, ok := scope.Lookup(name).(*types.Var)
+			if !ok || variable.Pos() >= pos || !IsContext(variable.Type()) {
+				continue
+			}
+			// The variable must not be shadowed in an inner scope
+			if _, object := innermost.LookupParent(name, pos); object != variable {
+				continue
+			}
+			if name == "ctx" {
+				return name
+			}
+			if found == "" {
+				found = name
+			}
+		}
+	}
+	return found
+}
+
+// IsContext returns whether t is context.Context
+func IsContext(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context"
+}
+
+// ErrorsPackageName returns the name referencing the errors package at pos, the name under which it can be imported,
+// or "" if the name is already used
+func ErrorsPackageName(pass *analysis.Pass, file *ast.File, pos token.Pos) string {
+	name := ImportName(file, ErrorsPath)
+	if name == "" {
+		if importsName(file, "errors") {
+			return ""
+		}
+		name = "errors"
+	}
+
+	_, object := pass.Pkg.Scope().Innermost(pos).LookupParent(name, pos)
+	if object == nil {
+		return name
+	}
+	pkgName, ok := object.(*types.PkgName)
+	if !ok || pkgName.Imported().Path() != ErrorsPath {
+		return ""
+	}
+	return name
+}
+
+// ImportName returns the name of the package path in the imports of file, or "" if it isn't imported
+func ImportName(file *ast.File, path string) string {
+	for _, spec := range file.Imports {
+		specPath, _ := strconv.Unquote(spec.Path.Value)
+		if specPath != path {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name
+		}
+		return path[strings.LastIndex(path, "/")+1:]
+	}
+	return ""
+}
+
+func importsName(file *ast.File, name string) bool {
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil && spec.Name.Name == name || spec.Name == nil && path[strings.LastIndex(path, "/")+1:] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// PackageUses returns the number of references to the package path in file
+func PackageUses(pass *analysis.Pass, file *ast.File, path string) int {
+	uses := 0
+	ast.Inspect(file, func(node ast.Node) bool {
+		ident, ok := node.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if pkgName, ok := pass.TypesInfo.Uses[ident].(*types.PkgName); ok && pkgName.Imported().Path() == path {
+			uses++
+		}
+		return true
+	})
+	return uses
+}
+
+// AddErrorsImport returns the edit importing the errors package in file, if it isn't imported yet
+func AddErrorsImport(file *ast.File) []analysis.TextEdit {
+	if ImportName(file, ErrorsPath) != "" || len(file.Imports) == 0 {
+		return nil
+	}
+	// The import is added to the declaration of the last import
+	lastImport := file.Imports[len(file.Imports)-1]
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT || genDecl.Pos() > lastImport.Pos() || genDecl.End() < lastImport.End() {
+			continue
+		}
+		if genDecl.Lparen.IsValid() {
+			return []analysis.TextEdit{{Pos: lastImport.End(), End: lastImport.End(), NewText: []byte("\n\t" + strconv.Quote(ErrorsPath))}}
+		}
+		return []analysis.TextEdit{{Pos: genDecl.End(), End: genDecl.End(), NewText: []byte("\nimport " + strconv.Quote(ErrorsPath))}}
+	}
+	return nil
+}
+
+// RemoveImport returns the edit removing the import of the package path from file
+func RemoveImport(file *ast.File, path string) []analysis.TextEdit {
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			importSpec := spec.(*ast.ImportSpec)
+			specPath, _ := strconv.Unquote(importSpec.Path.Value)
+			if specPath != path {
+				continue
+			}
+			if genDecl.Lparen.IsValid() {
+				return []analysis.TextEdit{{Pos: importSpec.Pos(), End: importSpec.End()}}
+			}
+			return []analysis.TextEdit{{Pos: genDecl.Pos(), End: genDecl.End()}}
+		}
+	}
+	return nil
+}
+
+// Source returns the source code of expr
+func Source(pass *analysis.Pass, expr ast.Expr) string {
+	file := pass.Fset.File(expr.Pos())
+	content, err := pass.ReadFile(file.Name())
+	if err != nil {
+		return types.ExprString(expr)
+	}
+	return string(content[file.Offset(expr.Pos()):file.Offset(expr.End())])
+}
+
+// IsNonNil returns whether the error expr at the cursor is known to be non-nil: it is a package level variable (a
+// sentinel error like io.EOF), or a variable checked by an enclosing `if err != nil` or `if err == io.EOF` whose body
+// contains the cursor. The variable is assumed not to be reassigned in the body.
+func IsNonNil(pass *analysis.Pass, cursor inspector.Cursor, expr ast.Expr) bool {
+	if isPackageVariable(pass, expr) {
+		return true
+	}
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	variable, ok := pass.TypesInfo.Uses[ident].(*types.Var)
+	if !ok {
+		return false
+	}
+
+	node := cursor.Node()
+	for enclosing := range cursor.Enclosing((*ast.IfStmt)(nil)) {
+		ifStmt := enclosing.Node().(*ast.IfStmt)
+		if node.Pos() < ifStmt.Body.Pos() || node.End() > ifStmt.Body.End() {
+			continue
+		}
+		if checksNonNil(pass, ifStmt.Cond, variable) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPackageVariable returns whether expr is a package level variable, e.g. io.EOF
+func isPackageVariable(pass *analysis.Pass, expr ast.Expr) bool {
+	var ident *ast.Ident
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		ident = e
+	case *ast.SelectorExpr:
+		ident = e.Sel
+	default:
+		return false
+	}
+	variable, ok := pass.TypesInfo.Uses[ident].(*types.Var)
+	return ok && variable.Pkg() != nil && variable.Parent() == variable.Pkg().Scope()
+}
+
+// checksNonNil returns whether cond is true only if the variable is not nil, e.g. `err != nil && retry`
+func checksNonNil(pass *analysis.Pass, cond ast.Expr, variable *types.Var) bool {
+	binary, ok := ast.Unparen(cond).(*ast.BinaryExpr)
+	if !ok {
+		return false
+	}
+	switch binary.Op {
+	case token.LAND:
+		return checksNonNil(pass, binary.X, variable) || checksNonNil(pass, binary.Y, variable)
+	case token.NEQ:
+		return isVariable(pass, binary.X, variable) && IsNil(pass, binary.Y) ||
+			isVariable(pass, binary.Y, variable) && IsNil(pass, binary.X)
+	case token.EQL:
+		return isVariable(pass, binary.X, variable) && isPackageVariable(pass, binary.Y) ||
+			isVariable(pass, binary.Y, variable) && isPackageVariable(pass, binary.X)
+	default:
+		return false
+	}
+}
+
+func isVariable(pass *analysis.Pass, expr ast.Expr, variable *types.Var) bool {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	return ok && pass.TypesInfo.Uses[ident] == variable
+}
+
+// IsNil returns whether expr is the nil identifier
+func IsNil(pass *analysis.Pass, expr ast.Expr) bool {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	_, ok = pass.TypesInfo.Uses[ident].(*types.Nil)
+	return ok
+}
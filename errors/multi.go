@@ -0,0 +1,143 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// maxSummarizedErrors is the number of errors detailed in the message of a MultiError
+const maxSummarizedErrors = 3
+
+// ItemError is the error of an item of a bulk operation
+type ItemError struct {
+	ID  string
+	Err error
+}
+
+func (err ItemError) Error() string {
+	return err.ID + ": " + err.Err.Error()
+}
+
+// Unwrap implements error management from the standard library
+func (err ItemError) Unwrap() error {
+	return err.Err
+}
+
+// MultiError collects the errors of the items of a bulk operation, e.g. deleting 500 storage objects. It is safe for
+// concurrent use. Is and As from the standard library match any of its errors.
+type MultiError struct {
+	lock  sync.Mutex
+	total int
+	errs  []ItemError
+}
+
+// NewMultiError returns a MultiError for a bulk operation on total items, 0 if the number of items is unknown
+func NewMultiError(total int) *MultiError {
+	return &MultiError{total: total}
+}
+
+// Add records the error of the item identified by id. The error is wrapped in an ErrCtx holding ctx, unless it is
+// already one. Nil errors are ignored.
+func (m *MultiError) Add(ctx context.Context, id string, err error) {
+	if err == nil {
+		return
+	}
+	if _, ok := err.(ErrCtx); !ok {
+		err = ErrCtx{ctx: ctx, err: err, stack: wrappingCallers(err)}
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.errs = append(m.errs, ItemError{ID: id, Err: err})
+}
+
+// Collect returns a function running fn and recording its error, e.g. for concurrency.ParallelWorker.Perform:
+//
+//	w.Perform(multiErr.Collect(ctx, object.Key, func(ctx context.Context) error {
+//		return retryer.Do(ctx, deleteObject(object))
+//	}))
+func (m *MultiError) Collect(ctx context.Context, id string, fn func(context.Context) error) func() {
+	return func() {
+		m.Add(ctx, id, fn(ctx))
+	}
+}
+
+// RetryFailed returns a function for retry.Retryer.Do running fn for each of the ids, then only for the failed ones at
+// the next attempts, so that the items which succeeded are not run again. The items are run sequentially. It returns
+// the MultiError of the last attempt, or nil.
+func RetryFailed(ids []string, fn func(ctx context.Context, id string) error) func(context.Context) error {
+	pending := ids
+	return func(ctx context.Context) error {
+		multiErr := NewMultiError(len(ids))
+		for _, id := range pending {
+			multiErr.Add(ctx, id, fn(ctx, id))
+		}
+
+		failed := make([]string, 0, multiErr.Len())
+		for _, err := range multiErr.Errors() {
+			failed = append(failed, err.ID)
+		}
+		pending = failed
+		return multiErr.ErrorOrNil()
+	}
+}
+
+// Len returns the number of errors recorded
+func (m *MultiError) Len() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return len(m.errs)
+}
+
+// Errors returns the errors recorded, in the order they were added
+func (m *MultiError) Errors() []ItemError {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return append([]ItemError(nil), m.errs...)
+}
+
+// ErrorOrNil returns nil if no error has been recorded, the MultiError otherwise. It avoids returning a nil
+// *MultiError as a non-nil error.
+func (m *MultiError) ErrorOrNil() error {
+	if m == nil || m.Len() == 0 {
+		return nil
+	}
+	return m
+}
+
+// Error summarizes the errors, e.g. "12 of 500 failed: a: not found; b: timeout; c: timeout (and 9 more)"
+func (m *MultiError) Error() string {
+	errs := m.Errors()
+
+	var message strings.Builder
+	if m.total > 0 {
+		fmt.Fprintf(&message, "%d of %d failed", len(errs), m.total)
+	} else {
+		fmt.Fprintf(&message, "%d failed", len(errs))
+	}
+	for i, err := range errs {
+		if i == maxSummarizedErrors {
+			fmt.Fprintf(&message, " (and %d more)", len(errs)-maxSummarizedErrors)
+			break
+		}
+		if i == 0 {
+			message.WriteString(": ")
+		} else {
+			message.WriteString("; ")
+		}
+		message.WriteString(err.Error())
+	}
+	return message.String()
+}
+
+// Unwrap implements error management from the standard library for Is and As
+func (m *MultiError) Unwrap() []error {
+	errs := m.Errors()
+	unwrapped := make([]error, 0, len(errs))
+	for _, err := range errs {
+		unwrapped = append(unwrapped, err)
+	}
+	return unwrapped
+}
@@ -0,0 +1,133 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiError(t *testing.T) {
+	t.Run("it is nil without error", func(t *testing.T) {
+		multiErr := NewMultiError(2)
+		multiErr.Add(context.Background(), "a", nil)
+
+		require.NoError(t, multiErr.ErrorOrNil())
+		var nilMultiErr *MultiError
+		require.NoError(t, nilMultiErr.ErrorOrNil())
+	})
+
+	t.Run("it summarizes the errors", func(t *testing.T) {
+		tests := map[string]struct {
+			total           int
+			errors          int
+			expectedMessage string
+		}{
+			"a few errors": {
+				total:           500,
+				errors:          2,
+				expectedMessage: "2 of 500 failed: object-0: fail 0; object-1: fail 1",
+			},
+			"many errors": {
+				total:           500,
+				errors:          12,
+				expectedMessage: "12 of 500 failed: object-0: fail 0; object-1: fail 1; object-2: fail 2 (and 9 more)",
+			},
+			"an unknown total": {
+				errors:          1,
+				expectedMessage: "1 failed: object-0: fail 0",
+			},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				multiErr := NewMultiError(test.total)
+				for i := range test.errors {
+					multiErr.Add(context.Background(), fmt.Sprintf("object-%d", i), fmt.Errorf("fail %d", i))
+				}
+
+				require.EqualError(t, multiErr.ErrorOrNil(), test.expectedMessage)
+			})
+		}
+	})
+
+	t.Run("it records the context of each item", func(t *testing.T) {
+		ctx1 := context.WithValue(context.Background(), testKey1, "value1")
+		ctx2 := context.WithValue(context.Background(), testKey2, "value2")
+		multiErr := NewMultiError(2)
+		multiErr.Add(ctx1, "a", errors.New("fail"))
+		multiErr.Add(context.Background(), "b", New(ctx2, "fail"))
+
+		errs := multiErr.Errors()
+		require.Len(t, errs, 2)
+		assert.Equal(t, "a", errs[0].ID)
+		assert.Equal(t, "value1", RootCtxOrFallback(context.Background(), errs[0]).Value(testKey1))
+		assert.Equal(t, "value2", RootCtxOrFallback(context.Background(), errs[1]).Value(testKey2))
+		assert.Equal(t, "value1", RootCtxOrFallback(context.Background(), multiErr).Value(testKey1))
+	})
+
+	t.Run("Is and As match any error", func(t *testing.T) {
+		multiErr := NewMultiError(0)
+		multiErr.Add(context.Background(), "a", errors.New("fail"))
+		multiErr.Add(context.Background(), "b", Wrap(context.Background(), ErrNotFound, "get object"))
+		multiErr.Add(context.Background(), "c", &CustomError{Code: 404, Message: "missing"})
+
+		err := multiErr.ErrorOrNil()
+		assert.ErrorIs(t, err, ErrNotFound)
+		var customErr *CustomError
+		require.ErrorAs(t, err, &customErr)
+		assert.Equal(t, 404, customErr.Code)
+		var itemErr ItemError
+		require.ErrorAs(t, err, &itemErr)
+		assert.Equal(t, "a", itemErr.ID)
+	})
+
+	t.Run("it is safe for concurrent use", func(t *testing.T) {
+		multiErr := NewMultiError(100)
+		var wg sync.WaitGroup
+		for i := range 100 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				multiErr.Collect(context.Background(), fmt.Sprint(i), func(context.Context) error {
+					if i%2 == 0 {
+						return nil
+					}
+					return errors.New("fail")
+				})()
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 50, multiErr.Len())
+	})
+}
+
+func TestRetryFailed(t *testing.T) {
+	ctx := context.Background()
+	var runs []string
+	attempts := map[string]int{}
+	retryable := RetryFailed([]string{"a", "b", "c"}, func(_ context.Context, id string) error {
+		runs = append(runs, id)
+		attempts[id]++
+		if id == "a" || (id == "b" && attempts[id] < 2) {
+			return errors.New("timeout")
+		}
+		return nil
+	})
+
+	err := retryable(ctx)
+	require.EqualError(t, err, "2 of 3 failed: a: timeout; b: timeout")
+
+	err = retryable(ctx)
+	require.EqualError(t, err, "1 of 3 failed: a: timeout")
+	var multiErr *MultiError
+	require.ErrorAs(t, err, &multiErr)
+	assert.Equal(t, "a", multiErr.Errors()[0].ID)
+
+	assert.Equal(t, []string{"a", "b", "c", "a", "b"}, runs)
+}
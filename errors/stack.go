@@ -0,0 +1,151 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// maxStackDepth is the maximum number of frames captured in a stack trace
+const maxStackDepth = 32
+
+var stackTraceEnabled atomic.Bool
+
+func init() {
+	enabled, _ := strconv.ParseBool(os.Getenv("ERRORS_STACK_TRACE"))
+	stackTraceEnabled.Store(enabled)
+}
+
+// EnableStackTrace enables or disables the capture of the stack trace by New, Newf, Errorf, Wrap and Wrapf. The
+// capture is enabled at startup if the ERRORS_STACK_TRACE environment variable is true.
+func EnableStackTrace(enabled bool) {
+	stackTraceEnabled.Store(enabled)
+}
+
+// stack is the program counters of the callers of the function creating an ErrCtx. It is stored as a pointer in
+// ErrCtx to keep it comparable.
+type stack []uintptr
+
+// callers captures the stack of the caller of the function creating the error, if the capture is enabled
+func callers() *stack {
+	if !stackTraceEnabled.Load() {
+		return nil
+	}
+	return captureStack()
+}
+
+// wrappingCallers captures the stack unless the wrapped error already has one, which is closer to the origin of the
+// error
+func wrappingCallers(err error) *stack {
+	if !stackTraceEnabled.Load() || hasStackTrace(err) {
+		return nil
+	}
+	return captureStack()
+}
+
+func captureStack() *stack {
+	var pcs [maxStackDepth]uintptr
+	// Skip runtime.Callers, captureStack, callers or wrappingCallers and the function of this package creating the error
+	n := runtime.Callers(4, pcs[:])
+	s := stack(pcs[:n])
+	return &s
+}
+
+func (s *stack) frames() []runtime.Frame {
+	if s == nil {
+		return nil
+	}
+	callersFrames := runtime.CallersFrames(*s)
+	frames := make([]runtime.Frame, 0, len(*s))
+	for {
+		frame, more := callersFrames.Next()
+		frames = append(frames, frame)
+		if !more {
+			return frames
+		}
+	}
+}
+
+// StackTrace returns the stack trace of the deepest error of the chain having one, or nil if none of them has a
+// stack trace. The errors of other versions of this package are supported.
+func StackTrace(err error) []runtime.Frame {
+	var frames []runtime.Frame
+	for unwrappedErr := err; unwrappedErr != nil; unwrappedErr = UnwrapError(unwrappedErr) {
+		if errCtx, ok := unwrappedErr.(ErrCtx); ok {
+			if errCtx.stack != nil {
+				frames = errCtx.stack.frames()
+			}
+			continue
+		}
+		stackTracer, ok := unwrappedErr.(interface{ StackTrace() []runtime.Frame })
+		if ok {
+			if stackTrace := stackTracer.StackTrace(); stackTrace != nil {
+				frames = stackTrace
+			}
+		}
+	}
+	return frames
+}
+
+func hasStackTrace(err error) bool {
+	for unwrappedErr := err; unwrappedErr != nil; unwrappedErr = UnwrapError(unwrappedErr) {
+		if errCtx, ok := unwrappedErr.(ErrCtx); ok {
+			if errCtx.stack != nil {
+				return true
+			}
+			continue
+		}
+		stackTracer, ok := unwrappedErr.(interface{ StackTrace() []runtime.Frame })
+		if ok && stackTracer.StackTrace() != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Format implements fmt.Formatter. The %+v verb prints the message and, if the stack trace has been captured, the
+// messages of the wrapped errors and the stack trace.
+func (err ErrCtx) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		_, _ = io.WriteString(s, err.Error())
+		frames := err.StackTrace()
+		if len(frames) > 0 {
+			writeWrapChain(s, err)
+			writeStackTrace(s, frames)
+		}
+	case verb == 'q':
+		_, _ = fmt.Fprintf(s, "%q", err.Error())
+	default:
+		_, _ = io.WriteString(s, err.Error())
+	}
+}
+
+// writeWrapChain writes the message added by each error of the chain, from the outermost to the root cause
+func writeWrapChain(w io.Writer, err error) {
+	_, _ = io.WriteString(w, "\nwrap chain:")
+
+	var previous error
+	for unwrappedErr := err; unwrappedErr != nil; unwrappedErr = UnwrapError(unwrappedErr) {
+		// Several errors of the chain may have the same message, e.g. an ErrCtx and its wrapped error
+		if previous != nil && previous.Error() != unwrappedErr.Error() {
+			message := strings.TrimSuffix(previous.Error(), ": "+unwrappedErr.Error())
+			_, _ = fmt.Fprintf(w, "\n    %s", message)
+		}
+		previous = unwrappedErr
+	}
+	if previous != nil {
+		_, _ = fmt.Fprintf(w, "\n    %s (%T)", previous.Error(), previous)
+	}
+}
+
+func writeStackTrace(w io.Writer, frames []runtime.Frame) {
+	_, _ = io.WriteString(w, "\nstack trace:")
+	for _, frame := range frames {
+		_, _ = fmt.Fprintf(w, "\n    %s\n        %s:%d", frame.Function, frame.File, frame.Line)
+	}
+}
@@ -0,0 +1,104 @@
+package errors
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func enableStackTrace(t *testing.T) {
+	t.Helper()
+
+	EnableStackTrace(true)
+	t.Cleanup(func() { EnableStackTrace(false) })
+}
+
+func newStackTestError(ctx context.Context) error {
+	return New(ctx, "connection refused")
+}
+
+func TestErrCtx_StackTrace(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("it does not capture the stack trace by default", func(t *testing.T) {
+		err := Wrap(ctx, newStackTestError(ctx), "fetch app")
+
+		assert.Nil(t, StackTrace(err))
+		assert.NotContains(t, fmt.Sprintf("%+v", err), "stack trace:")
+	})
+
+	t.Run("it captures the stack trace of the function creating the error", func(t *testing.T) {
+		enableStackTrace(t)
+
+		err := newStackTestError(ctx)
+
+		var errCtx ErrCtx
+		require.True(t, As(err, &errCtx))
+		frames := errCtx.StackTrace()
+		require.NotEmpty(t, frames)
+		assert.True(t, strings.HasSuffix(frames[0].Function, ".newStackTestError"), frames[0].Function)
+	})
+
+	t.Run("it keeps the stack trace of the wrapped error", func(t *testing.T) {
+		enableStackTrace(t)
+
+		err := Wrapf(ctx, Wrap(ctx, newStackTestError(ctx), "fetch app"), "deploy %s", "my-app")
+
+		frames := StackTrace(err)
+		require.NotEmpty(t, frames)
+		assert.True(t, strings.HasSuffix(frames[0].Function, ".newStackTestError"), frames[0].Function)
+	})
+
+	t.Run("it captures the stack trace when wrapping an error without stack trace", func(t *testing.T) {
+		enableStackTrace(t)
+
+		err := Wrap(ctx, stdErrors.New("connection refused"), "fetch app")
+
+		frames := StackTrace(err)
+		require.NotEmpty(t, frames)
+		assert.True(t, strings.HasSuffix(frames[0].Function, ".TestErrCtx_StackTrace.func4"), frames[0].Function)
+	})
+}
+
+func TestErrCtx_Format(t *testing.T) {
+	ctx := context.Background()
+	err := Wrap(ctx, Wrap(ctx, stdErrors.New("connection refused"), "fetch app"), "deploy app")
+
+	t.Run("it prints the message", func(t *testing.T) {
+		assert.Equal(t, "deploy app: fetch app: connection refused", fmt.Sprintf("%v", err))
+		assert.Equal(t, "deploy app: fetch app: connection refused", fmt.Sprintf("%s", err))
+		assert.Equal(t, `"deploy app: fetch app: connection refused"`, fmt.Sprintf("%q", err))
+	})
+
+	t.Run("it only prints the message with the + flag if the stack trace is not captured", func(t *testing.T) {
+		assert.Equal(t, "deploy app: fetch app: connection refused", fmt.Sprintf("%+v", err))
+	})
+
+	t.Run("it prints the wrap chain with the + flag", func(t *testing.T) {
+		enableStackTrace(t)
+		err := Wrap(ctx, Wrap(ctx, stdErrors.New("connection refused"), "fetch app"), "deploy app")
+
+		assert.True(t, strings.HasPrefix(fmt.Sprintf("%+v", err), `deploy app: fetch app: connection refused
+wrap chain:
+    deploy app
+    fetch app
+    connection refused (*errors.errorString)
+stack trace:
+`))
+	})
+
+	t.Run("it prints the stack trace with the + flag", func(t *testing.T) {
+		enableStackTrace(t)
+
+		output := fmt.Sprintf("%+v", newStackTestError(ctx))
+
+		assert.Contains(t, output, "\nstack trace:\n")
+		assert.Contains(t, output, ".newStackTestError\n")
+		assert.Contains(t, output, "stack_test.go:")
+	})
+}
@@ -0,0 +1,137 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Scalingo/go-utils/errors/v3"
+)
+
+// builtinParamCheckers checks the parameters of the built-in rules, before they are applied
+func builtinParamCheckers() map[string]func(param string) error {
+	return map[string]func(param string) error{
+		"min": checkNumberParam,
+		"max": checkNumberParam,
+	}
+}
+
+func checkNumberParam(param string) error {
+	_, err := strconv.ParseFloat(param, 64)
+	return err
+}
+
+func builtinRules() map[string]Rule {
+	return map[string]Rule{
+		"required": required,
+		"min":      minRule,
+		"max":      maxRule,
+		"email":    email,
+		"oneof":    oneOf,
+	}
+}
+
+func required(value reflect.Value, _ string) *errors.FieldError {
+	if !isEmpty(value) {
+		return nil
+	}
+	return &errors.FieldError{Code: "required", Message: "should not be empty"}
+}
+
+func minRule(value reflect.Value, param string) *errors.FieldError {
+	return compare(value, "min", param, func(size, limit float64) bool { return size >= limit }, "too_short", "too_small")
+}
+
+func maxRule(value reflect.Value, param string) *errors.FieldError {
+	return compare(value, "max", param, func(size, limit float64) bool { return size <= limit }, "too_long", "too_big")
+}
+
+// compare checks the length of the strings, slices and maps, or the value of the numbers against the parameter
+func compare(value reflect.Value, name, param string, valid func(size, limit float64) bool, lengthCode, numberCode string) *errors.FieldError {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		// Unreachable as the parameter is checked by checkNumberParam
+		return nil
+	}
+	params := map[string]any{name: param}
+
+	switch value.Kind() {
+	case reflect.String:
+		if valid(float64(utf8.RuneCountInString(value.String())), limit) {
+			return nil
+		}
+		return &errors.FieldError{Code: lengthCode, Message: fmt.Sprintf("should have a length %s %s", comparison(name), param), Params: params}
+	case reflect.Slice, reflect.Map, reflect.Array:
+		if valid(float64(value.Len()), limit) {
+			return nil
+		}
+		return &errors.FieldError{Code: lengthCode, Message: fmt.Sprintf("should have %s %s elements", comparison(name), param), Params: params}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if valid(float64(value.Int()), limit) {
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if valid(float64(value.Uint()), limit) {
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if valid(value.Float(), limit) {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return &errors.FieldError{Code: numberCode, Message: fmt.Sprintf("should be %s %s", comparison(name), param), Params: params}
+}
+
+func comparison(name string) string {
+	if name == "min" {
+		return "at least"
+	}
+	return "at most"
+}
+
+func email(value reflect.Value, _ string) *errors.FieldError {
+	if value.Kind() != reflect.String {
+		return nil
+	}
+	address, err := mail.ParseAddress(value.String())
+	if err == nil && address.Address == value.String() {
+		return nil
+	}
+	return &errors.FieldError{Code: "invalid_email", Message: "should be a valid email address"}
+}
+
+// oneOf checks that the value is one of the space-separated values of the parameter
+func oneOf(value reflect.Value, param string) *errors.FieldError {
+	allowed := strings.Fields(param)
+	if slices.Contains(allowed, formatValue(value)) {
+		return nil
+	}
+	return &errors.FieldError{
+		Code:    "not_included",
+		Message: "should be one of " + strings.Join(allowed, ", "),
+		Params:  map[string]any{"oneof": allowed},
+	}
+}
+
+// formatValue formats the value without calling Interface, which panics on the fields promoted from an unexported
+// embedded struct
+func formatValue(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64)
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	default:
+		return value.String()
+	}
+}
@@ -0,0 +1,238 @@
+// Package validation validates the structs according to the rules of their `validate` tags:
+//
+//	type App struct {
+//		Name  string `json:"name" validate:"required,max=255"`
+//		Email string `json:"email" validate:"email"`
+//		Stack string `json:"stack" validate:"oneof=scalingo-20 scalingo-22"`
+//	}
+//
+// The errors are returned as *errors.ValidationErrors, the fields being named after their json or bson tag.
+package validation
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/Scalingo/go-utils/errors/v3"
+)
+
+const tagName = "validate"
+
+// maxDepth is the maximum depth of the nested structs validated
+const maxDepth = 32
+
+// Rule checks a value against the parameter of the rule, e.g. "255" for `max=255`. It returns nil if the value is
+// valid. The rules other than "required" are not applied to the zero values.
+type Rule func(value reflect.Value, param string) *errors.FieldError
+
+// Validator validates the structs with the built-in rules and the registered ones
+type Validator struct {
+	lock  sync.RWMutex
+	rules map[string]Rule
+	// paramCheckers checks the parameters of the rules, a Rule cannot return an error
+	paramCheckers map[string]func(param string) error
+}
+
+// New returns a Validator with the built-in rules: required, min, max, email and oneof
+func New() *Validator {
+	return &Validator{rules: builtinRules(), paramCheckers: builtinParamCheckers()}
+}
+
+var defaultValidator = New()
+
+// RegisterRule registers a rule in the default validator
+func RegisterRule(name string, rule Rule) {
+	defaultValidator.RegisterRule(name, rule)
+}
+
+// Struct validates v with the default validator
+func Struct(ctx context.Context, v any) error {
+	return defaultValidator.Struct(ctx, v)
+}
+
+// RegisterRule registers a rule used by the tags `validate:"name"` or `validate:"name=param"`. It replaces the rule
+// with the same name.
+func (v *Validator) RegisterRule(name string, rule Rule) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.rules[name] = rule
+	delete(v.paramCheckers, name)
+}
+
+// Struct validates the fields of the struct s, including the nested structs, slices and maps of structs. It returns a
+// *errors.ValidationErrors if a field is invalid, or an error if a tag references an unknown rule or has an invalid
+// parameter, e.g. `max=ten`.
+func (v *Validator) Struct(ctx context.Context, s any) error {
+	value := reflect.ValueOf(s)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return errors.New(ctx, "validate a nil pointer")
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return errors.Newf(ctx, "validate a %s, a struct is expected", value.Kind())
+	}
+
+	builder := errors.NewValidationErrorsBuilder()
+	err := v.validateStruct(ctx, builder, "", value, 0)
+	if err != nil {
+		return err
+	}
+	return builder.Build()
+}
+
+func (v *Validator) validateStruct(ctx context.Context, builder *errors.ValidationErrorsBuilder, path errors.Path, value reflect.Value, depth int) error {
+	if depth > maxDepth {
+		return nil
+	}
+
+	valueType := value.Type()
+	for i := range valueType.NumField() {
+		field := valueType.Field(i)
+		// The exported fields of an embedded struct are promoted even if its type is unexported
+		if !field.IsExported() && (!field.Anonymous || indirectType(field.Type).Kind() != reflect.Struct) {
+			continue
+		}
+		tag := field.Tag.Get(tagName)
+		if tag == "-" {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		fieldPath := path
+		if !field.Anonymous {
+			fieldPath = path.Field(fieldName(field))
+		}
+
+		if tag != "" {
+			err := v.validateField(ctx, builder, fieldPath, fieldValue, tag)
+			if err != nil {
+				return err
+			}
+		}
+
+		err := v.validateNested(ctx, builder, fieldPath, fieldValue, depth+1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateNested validates the structs contained in the value
+func (v *Validator) validateNested(ctx context.Context, builder *errors.ValidationErrorsBuilder, path errors.Path, value reflect.Value, depth int) error {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		return v.validateStruct(ctx, builder, path, value, depth)
+	case reflect.Slice, reflect.Array:
+		for i := range value.Len() {
+			err := v.validateNested(ctx, builder, path.Index(i), value.Index(i), depth)
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		iterator := value.MapRange()
+		for iterator.Next() {
+			err := v.validateNested(ctx, builder, path.Field(iterator.Key().String()), iterator.Value(), depth)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *Validator) validateField(ctx context.Context, builder *errors.ValidationErrorsBuilder, path errors.Path, value reflect.Value, tag string) error {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+
+	for _, ruleTag := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(ruleTag), "=")
+		if name == "" {
+			continue
+		}
+		rule, ok := v.rules[name]
+		if !ok {
+			return errors.Newf(ctx, "unknown validation rule %q of field %s", name, path)
+		}
+		if checkParam, ok := v.paramCheckers[name]; ok {
+			err := checkParam(param)
+			if err != nil {
+				return errors.Wrapf(ctx, err, "invalid parameter %q of the validation rule %q of field %s", param, name, path)
+			}
+		}
+
+		if name == "required" {
+			if fieldErr := rule(value, param); fieldErr != nil {
+				builder.SetError(path.String(), *fieldErr)
+				// The other rules are meaningless on a missing value
+				return nil
+			}
+			continue
+		}
+		if isEmpty(value) {
+			continue
+		}
+		if fieldErr := rule(indirect(value), param); fieldErr != nil {
+			builder.SetError(path.String(), *fieldErr)
+		}
+	}
+	return nil
+}
+
+// fieldName returns the name of the field in the json or bson tag, or the Go name otherwise
+func fieldName(field reflect.StructField) string {
+	for _, tagKey := range []string{"json", "bson"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tagKey), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return value
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// isEmpty returns whether the value is nil, zero or has a zero length
+func isEmpty(value reflect.Value) bool {
+	value = indirect(value)
+	switch value.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Pointer, reflect.Interface:
+		return value.IsNil()
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return value.Len() == 0
+	default:
+		return value.IsZero()
+	}
+}
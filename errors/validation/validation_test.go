@@ -0,0 +1,155 @@
+package validation
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Scalingo/go-utils/errors/v3"
+)
+
+type container struct {
+	Name string `json:"name" validate:"required"`
+	Size int    `json:"size" validate:"min=1,max=10"`
+}
+
+type timestamps struct {
+	CreatedBy string `bson:"created_by" validate:"required"`
+}
+
+type app struct {
+	timestamps
+	Name       string            `json:"name" validate:"required,max=8"`
+	Email      string            `json:"email,omitempty" validate:"email"`
+	Stack      string            `bson:"stack" validate:"oneof=scalingo-20 scalingo-22"`
+	Owner      *string           `json:"owner" validate:"required"`
+	Containers []container       `json:"containers" validate:"max=2"`
+	Addons     map[string]*addon `json:"addons"`
+	Internal   string            `json:"-" validate:"-"`
+}
+
+type addon struct {
+	Plan string `json:"plan" validate:"required"`
+}
+
+func TestValidator_Struct(t *testing.T) {
+	ctx := context.Background()
+	owner := "john"
+
+	tests := map[string]struct {
+		value          any
+		expectedErrors map[string][]string
+		expectedCodes  map[string][]string
+	}{
+		"a valid struct": {
+			value: &app{
+				timestamps: timestamps{CreatedBy: "john"},
+				Name:       "my-app",
+				Email:      "john@example.com",
+				Stack:      "scalingo-22",
+				Owner:      &owner,
+				Containers: []container{{Name: "web", Size: 2}},
+				Addons:     map[string]*addon{"postgresql": {Plan: "starter"}},
+			},
+		},
+		"the optional fields are not validated when empty": {
+			value: app{timestamps: timestamps{CreatedBy: "john"}, Name: "my-app", Owner: &owner},
+		},
+		"invalid fields": {
+			value: &app{
+				Name:       "my-long-app",
+				Email:      "John <john@example.com>",
+				Stack:      "heroku-22",
+				Containers: []container{{Size: 11}, {Name: "worker", Size: 1}, {Name: "clock", Size: 1}},
+				Addons:     map[string]*addon{"postgresql": {}},
+			},
+			expectedErrors: map[string][]string{
+				"created_by":             {"should not be empty"},
+				"name":                   {"should have a length at most 8"},
+				"email":                  {"should be a valid email address"},
+				"stack":                  {"should be one of scalingo-20, scalingo-22"},
+				"owner":                  {"should not be empty"},
+				"containers":             {"should have at most 2 elements"},
+				"containers[0].name":     {"should not be empty"},
+				"containers[0].size":     {"should be at most 10"},
+				"addons.postgresql.plan": {"should not be empty"},
+			},
+			expectedCodes: map[string][]string{
+				"created_by":             {"required"},
+				"name":                   {"too_long"},
+				"email":                  {"invalid_email"},
+				"stack":                  {"not_included"},
+				"owner":                  {"required"},
+				"containers":             {"too_long"},
+				"containers[0].name":     {"required"},
+				"containers[0].size":     {"too_big"},
+				"addons.postgresql.plan": {"required"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := New().Struct(ctx, test.value)
+			if test.expectedErrors == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var validationErr *errors.ValidationErrors
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, test.expectedErrors, validationErr.Errors)
+			for field, codes := range test.expectedCodes {
+				var actualCodes []string
+				for _, detail := range validationErr.Details[field] {
+					actualCodes = append(actualCodes, detail.Code)
+				}
+				assert.Equal(t, codes, actualCodes, field)
+			}
+		})
+	}
+
+	t.Run("it fails with an unknown rule", func(t *testing.T) {
+		err := New().Struct(ctx, struct {
+			Name string `validate:"slug"`
+		}{Name: "my-app"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown validation rule "slug" of field Name`)
+	})
+
+	t.Run("it fails with an invalid parameter", func(t *testing.T) {
+		err := New().Struct(ctx, struct {
+			Name string `validate:"max=ten"`
+		}{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid parameter "ten" of the validation rule "max" of field Name`)
+	})
+
+	t.Run("it fails if the value is not a struct", func(t *testing.T) {
+		err := New().Struct(ctx, "my-app")
+		require.EqualError(t, err, "validate a string, a struct is expected")
+	})
+}
+
+func TestValidator_RegisterRule(t *testing.T) {
+	validator := New()
+	validator.RegisterRule("prefix", func(value reflect.Value, param string) *errors.FieldError {
+		if strings.HasPrefix(value.String(), param) {
+			return nil
+		}
+		return &errors.FieldError{Code: "invalid_prefix", Message: "should start with " + param}
+	})
+
+	err := validator.Struct(context.Background(), struct {
+		Name string `json:"name" validate:"required,prefix=sc-"`
+	}{Name: "my-app"})
+
+	var validationErr *errors.ValidationErrors
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, map[string][]string{"name": {"should start with sc-"}}, validationErr.Errors)
+	assert.Equal(t, "invalid_prefix", validationErr.Details["name"][0].Code)
+}
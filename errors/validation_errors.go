@@ -2,29 +2,76 @@ package errors
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
 	"strings"
 )
 
+// DefaultValidationErrorCode is the code of the errors set without code
+const DefaultValidationErrorCode = "invalid"
+
 // ValidationErrors store each errors associated to every fields of a model
 type ValidationErrors struct {
 	Errors map[string][]string `json:"errors"`
+	// Details contains the machine-readable code of each error of Errors. It is only set if a code has been given with
+	// SetError, so that the JSON of the errors set with Set is unchanged.
+	Details map[string][]FieldError `json:"details,omitempty"`
+}
+
+// FieldError is an error of a field with a machine-readable code, e.g. "too_long", and the parameters of the
+// validation rule, e.g. {"max": 64}
+type FieldError struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Params  map[string]any `json:"params,omitempty"`
 }
 
+// Error returns the errors sorted by field
 func (v *ValidationErrors) Error() string {
 	var builder strings.Builder
-	index := 0
 
-	for field, errs := range v.Errors {
-		index++
-		builder.WriteString(fmt.Sprintf("%s=%s", field, strings.Join(errs, ", ")))
-		if index < len(v.Errors) {
+	for i, field := range slices.Sorted(maps.Keys(v.Errors)) {
+		if i > 0 {
 			builder.WriteString(" ")
 		}
+		builder.WriteString(fmt.Sprintf("%s=%s", field, strings.Join(v.Errors[field], ", ")))
 	}
 
 	return builder.String()
 }
 
+// Path is the path of a field in nested objects and arrays, e.g. containers[2].env.KEY
+type Path string
+
+// Field returns the path of a field of the object at p
+func (p Path) Field(name string) Path {
+	return p.join(name)
+}
+
+// Index returns the path of an element of the array at p
+func (p Path) Index(index int) Path {
+	return p + Path("["+strconv.Itoa(index)+"]")
+}
+
+func (p Path) String() string {
+	return string(p)
+}
+
+// join appends a relative path, which can start with an index
+func (p Path) join(relative string) Path {
+	if p == "" {
+		return Path(relative)
+	}
+	if relative == "" {
+		return p
+	}
+	if relative[0] == '[' {
+		return p + Path(relative)
+	}
+	return p + "." + Path(relative)
+}
+
 // ValidationErrorsBuilder is used to provide a simple way to create a ValidationErrors struct. The typical usecase is:
 //
 //	func (m *MyModel) Validate(ctx context.Context) *ValidationErrors {
@@ -40,28 +87,61 @@ func (v *ValidationErrors) Error() string {
 //
 //		return validations.Build()
 //	}
+//
+// The fields of nested objects and arrays are set with a Path:
+//
+//	validations.SetError(errors.Path("containers").Index(2).Field("env").Field(key).String(), errors.FieldError{
+//		Code: "too_long", Message: "is too long", Params: map[string]any{"max": 64},
+//	})
 type ValidationErrorsBuilder struct {
-	errors map[string][]string
+	errors  map[string][]string
+	details map[string][]FieldError
+	// withCodes is true once a code has been given, the details are only built in this case
+	withCodes bool
 }
 
 // NewValidationErrors return an empty ValidationErrors struct
 func NewValidationErrorsBuilder() *ValidationErrorsBuilder {
 	return &ValidationErrorsBuilder{
-		errors: make(map[string][]string),
+		errors:  make(map[string][]string),
+		details: make(map[string][]FieldError),
 	}
 }
 
-// Set will add an error on a specific field, if the field already contains an error, it will just add it to the current errors list
+// Set will add an error on a specific field, if the field already contains an error, it will just add it to the current errors list.
+// The code of the error is DefaultValidationErrorCode.
 func (v *ValidationErrorsBuilder) Set(field, err string) *ValidationErrorsBuilder {
-	v.errors[field] = append(v.errors[field], err)
+	v.add(field, FieldError{Code: DefaultValidationErrorCode, Message: err})
+	return v
+}
+
+// SetError adds an error with a code on a specific field. Its message is added to the errors of the field. The code
+// defaults to DefaultValidationErrorCode.
+func (v *ValidationErrorsBuilder) SetError(field string, err FieldError) *ValidationErrorsBuilder {
+	if err.Code == "" {
+		err.Code = DefaultValidationErrorCode
+	} else {
+		v.withCodes = true
+	}
+	v.add(field, err)
 	return v
 }
 
+func (v *ValidationErrorsBuilder) add(field string, err FieldError) {
+	v.errors[field] = append(v.errors[field], err.Message)
+	v.details[field] = append(v.details[field], err)
+}
+
 // Get will return all errors set for a specific field
 func (v *ValidationErrorsBuilder) Get(field string) []string {
 	return v.errors[field]
 }
 
+// GetErrors returns the errors with their code set for a specific field
+func (v *ValidationErrorsBuilder) GetErrors(field string) []FieldError {
+	return v.details[field]
+}
+
 // Merge ValidationErrors with another ValidationErrors
 func (v *ValidationErrorsBuilder) Merge(verr *ValidationErrors) *ValidationErrorsBuilder {
 	return v.MergeWithPrefix("", verr)
@@ -77,21 +157,49 @@ func (v *ValidationErrorsBuilder) MergeWithPrefix(prefix string, verr *Validatio
 		prefix = prefix + "_"
 	}
 
-	for key, values := range verr.Errors {
-		for _, value := range values {
-			v.Set(prefix+key, value)
-		}
+	for key := range verr.Errors {
+		v.mergeField(prefix+key, verr, key)
+	}
+	return v
+}
+
+// MergeWithPath merges ValidationErrors in another ValidationErrors, the fields being relative to path:
+//
+//	validations.MergeWithPath(errors.Path("containers").Index(2), containerErr) // "name" becomes "containers[2].name"
+func (v *ValidationErrorsBuilder) MergeWithPath(path Path, verr *ValidationErrors) *ValidationErrorsBuilder {
+	if verr == nil {
+		return v
+	}
+
+	for key := range verr.Errors {
+		v.mergeField(path.join(key).String(), verr, key)
 	}
 	return v
 }
 
+// mergeField sets the errors of a field of verr, with their code if verr has details
+func (v *ValidationErrorsBuilder) mergeField(field string, verr *ValidationErrors, key string) {
+	details := verr.Details[key]
+	if len(details) != len(verr.Errors[key]) {
+		for _, message := range verr.Errors[key] {
+			v.Set(field, message)
+		}
+		return
+	}
+	for _, detail := range details {
+		v.SetError(field, detail)
+	}
+}
+
 // Build will send a ValidationErrors struct if there is some errors or nil if no errors has been defined
 func (v *ValidationErrorsBuilder) Build() error {
 	if len(v.errors) == 0 {
 		return nil
 	}
 
-	return &ValidationErrors{
-		Errors: v.errors,
+	verr := &ValidationErrors{Errors: v.errors}
+	if v.withCodes {
+		verr.Details = v.details
 	}
+	return verr
 }
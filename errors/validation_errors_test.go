@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 
@@ -137,3 +138,84 @@ func TestValidationErrors_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestValidationErrors_Error_Sorted(t *testing.T) {
+	validationErrors := ValidationErrors{
+		Errors: map[string][]string{
+			"type":          {"invalid type"},
+			"containers[0]": {"is empty"},
+			"name":          {"invalid name", "is too long"},
+		},
+	}
+
+	assert.Equal(t, "containers[0]=is empty name=invalid name, is too long type=invalid type", validationErrors.Error())
+}
+
+func TestPath(t *testing.T) {
+	path := Path("containers").Index(2).Field("env").Field("KEY")
+
+	assert.Equal(t, "containers[2].env.KEY", path.String())
+	assert.Equal(t, "name", Path("").Field("name").String())
+	assert.Equal(t, "[1]", Path("").Index(1).String())
+}
+
+func TestValidationErrorsBuilder_SetError(t *testing.T) {
+	err := NewValidationErrorsBuilder().
+		Set("name", "is empty").
+		SetError("env.KEY", FieldError{Code: "too_long", Message: "is too long", Params: map[string]any{"max": 64}}).
+		Build()
+
+	var verr *ValidationErrors
+	require.True(t, errors.As(err, &verr))
+	assert.Equal(t, map[string][]string{"name": {"is empty"}, "env.KEY": {"is too long"}}, verr.Errors)
+	assert.Equal(t, map[string][]FieldError{
+		"name":    {{Code: DefaultValidationErrorCode, Message: "is empty"}},
+		"env.KEY": {{Code: "too_long", Message: "is too long", Params: map[string]any{"max": 64}}},
+	}, verr.Details)
+
+	serialized, jsonErr := json.Marshal(verr)
+	require.NoError(t, jsonErr)
+	assert.JSONEq(t, `{
+		"errors": {"name": ["is empty"], "env.KEY": ["is too long"]},
+		"details": {
+			"name": [{"code": "invalid", "message": "is empty"}],
+			"env.KEY": [{"code": "too_long", "message": "is too long", "params": {"max": 64}}]
+		}
+	}`, string(serialized))
+}
+
+func TestValidationErrorsBuilder_Build_WithoutCodes(t *testing.T) {
+	err := NewValidationErrorsBuilder().
+		Set("name", "is empty").
+		SetError("env.KEY", FieldError{Message: "is too long"}).
+		Build()
+
+	var verr *ValidationErrors
+	require.True(t, errors.As(err, &verr))
+	assert.Nil(t, verr.Details)
+
+	serialized, jsonErr := json.Marshal(verr)
+	require.NoError(t, jsonErr)
+	assert.JSONEq(t, `{"errors": {"name": ["is empty"], "env.KEY": ["is too long"]}}`, string(serialized))
+}
+
+func TestValidationErrorsBuilder_MergeWithPath(t *testing.T) {
+	containerErr := NewValidationErrorsBuilder().
+		SetError("env.KEY", FieldError{Code: "too_long", Message: "is too long"}).
+		Set("[0]", "is invalid").
+		Build()
+	var containerVerr *ValidationErrors
+	require.True(t, errors.As(containerErr, &containerVerr))
+	// ValidationErrors created without the builder have no details
+	legacyVerr := &ValidationErrors{Errors: map[string][]string{"name": {"is empty"}}}
+
+	builder := NewValidationErrorsBuilder().
+		MergeWithPath(Path("containers").Index(2), containerVerr).
+		MergeWithPath(Path("app"), legacyVerr).
+		MergeWithPath(Path("app"), nil)
+
+	assert.Equal(t, []string{"is too long"}, builder.Get("containers[2].env.KEY"))
+	assert.Equal(t, []FieldError{{Code: "too_long", Message: "is too long"}}, builder.GetErrors("containers[2].env.KEY"))
+	assert.Equal(t, []string{"is invalid"}, builder.Get("containers[2][0]"))
+	assert.Equal(t, []FieldError{{Code: DefaultValidationErrorCode, Message: "is empty"}}, builder.GetErrors("app.name"))
+}
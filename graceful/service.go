@@ -34,6 +34,8 @@ type Service struct {
 	// pidFile tracks the pid of the last child among the chain of graceful restart
 	// Required for daemon manager to track the service
 	pidFile string
+	// shutdownHooks are called once the servers are stopped, e.g. to flush buffered data
+	shutdownHooks []func(context.Context) error
 }
 
 type Option func(*Service)
@@ -76,6 +78,14 @@ func WithNumServers(n int) Option {
 	})
 }
 
+// WithShutdownHooks registers functions called once the servers are stopped, before the service exits. They share a
+// timeout of the wait duration. E.g. logger.CloseAsyncOutputs flushes the buffered log entries.
+func WithShutdownHooks(hooks ...func(context.Context) error) Option {
+	return Option(func(s *Service) {
+		s.shutdownHooks = append(s.shutdownHooks, hooks...)
+	})
+}
+
 func (s *Service) initTableflipUpgrader(ctx context.Context) error {
 	var err error
 	s.mx.Lock()
@@ -185,6 +195,7 @@ func (s *Service) finalize(ctx context.Context) error {
 	log := logger.Get(ctx)
 
 	defer s.upg.Stop()
+	defer s.runShutdownHooks(ctx)
 
 	log.Info("Ready")
 	if err := s.upg.Ready(); err != nil {
@@ -230,6 +241,24 @@ func (s *Service) finalize(ctx context.Context) error {
 	return nil
 }
 
+// runShutdownHooks calls the shutdown hooks with a dedicated timeout as the shutdown may have consumed the one of the
+// service
+func (s *Service) runShutdownHooks(ctx context.Context) {
+	if len(s.shutdownHooks) == 0 {
+		return
+	}
+	log := logger.Get(ctx)
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.waitDuration)
+	defer cancel()
+	for _, hook := range s.shutdownHooks {
+		err := hook(ctx)
+		if err != nil {
+			log.WithError(err).Error("Shutdown hook failed")
+		}
+	}
+}
+
 // IncConnCount has to be used when connections are hijacked because in
 // this case http.Server doesn't track these connection anymore, but you
 // may not want to cut them abrutely.
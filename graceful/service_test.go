@@ -2,6 +2,7 @@ package graceful
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -533,3 +534,30 @@ func (c *cmdAndOutput) findProcess() *os.Process {
 	require.NoError(c.t, err)
 	return p
 }
+
+func TestService_runShutdownHooks(t *testing.T) {
+	var calls []string
+	var hookCtxErr error
+	s := NewService(
+		WithWaitDuration(time.Second),
+		WithShutdownHooks(
+			func(ctx context.Context) error {
+				calls = append(calls, "first")
+				return errors.New("flush failed")
+			},
+			func(ctx context.Context) error {
+				calls = append(calls, "second")
+				hookCtxErr = ctx.Err()
+				return nil
+			},
+		),
+	)
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	s.runShutdownHooks(ctx)
+
+	assert.Equal(t, []string{"first", "second"}, calls)
+	// The hooks have their own timeout even if the service context is canceled
+	assert.NoError(t, hookCtxErr)
+}
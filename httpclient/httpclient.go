@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/gofrs/uuid/v5"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 type ClientOpt func(c *client)
@@ -68,19 +69,26 @@ type reqidTransport struct {
 }
 
 func (t reqidTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	if req.Header.Get("X-Request-ID") != "" {
-		return t.parent.RoundTrip(req)
+	ctx := req.Context()
+	if req.Header.Get(requestIDHeader) == "" {
+		reqID, ok := RequestIDFromContext(ctx)
+		if !ok {
+			uuid, err := uuid.NewV4()
+			if err != nil {
+				return nil, fmt.Errorf("fail to generate UUID for X-Request-ID: %v", err)
+			}
+			reqID = uuid.String()
+		}
+		req.Header.Set(requestIDHeader, reqID)
 	}
 
-	reqID, ok := req.Context().Value("request_id").(string)
-	if !ok {
-		uuid, err := uuid.NewV4()
-		if err != nil {
-			return nil, fmt.Errorf("fail to generate UUID for X-Request-ID: %v", err)
-		}
-		reqID = uuid.String()
+	// Only inject the W3C headers if the caller did not set them explicitly
+	if req.Header.Get(traceparentHeader) == "" {
+		traceContextPropagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
+	if req.Header.Get(baggageHeader) == "" {
+		baggagePropagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
 	}
-	req.Header.Set("X-Request-ID", reqID)
 	return t.parent.RoundTrip(req)
 }
 
@@ -10,11 +10,14 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestNewClient(t *testing.T) {
 	cases := []struct {
 		Name    string
+		Header  string
 		Context context.Context
 		Expect  func(*testing.T, string)
 	}{
@@ -30,13 +33,35 @@ func TestNewClient(t *testing.T) {
 			Expect: func(t *testing.T, body string) {
 				assert.Len(t, body, 36)
 			},
+		}, {
+			Name:    "it should not add a traceparent header if there is no span in the context",
+			Header:  "traceparent",
+			Context: context.Background(),
+			Expect: func(t *testing.T, body string) {
+				assert.Empty(t, body)
+			},
+		}, {
+			Name:   "it should add a traceparent header if a span is present in the context",
+			Header: "traceparent",
+			Context: trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+				SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+				TraceFlags: trace.FlagsSampled,
+			})),
+			Expect: func(t *testing.T, body string) {
+				assert.Equal(t, testTraceparent, body)
+			},
 		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.Name, func(t *testing.T) {
+			header := c.Header
+			if header == "" {
+				header = "X-Request-ID"
+			}
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				fmt.Fprint(w, r.Header.Get("X-Request-ID"))
+				fmt.Fprint(w, r.Header.Get(header))
 			}))
 			defer server.Close()
 
@@ -53,6 +78,29 @@ func TestNewClient(t *testing.T) {
 			c.Expect(t, string(body))
 		})
 	}
+
+	t.Run("it should add the baggage header even if the caller set the traceparent header", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s|%s", r.Header.Get("traceparent"), r.Header.Get("baggage"))
+		}))
+		defer server.Close()
+
+		member, err := baggage.NewMember("tenant", "scalingo")
+		require.NoError(t, err)
+		bag, err := baggage.New(member)
+		require.NoError(t, err)
+		req, err := http.NewRequestWithContext(baggage.ContextWithBaggage(context.Background(), bag), http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("traceparent", testTraceparent)
+
+		res, err := NewClient().Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, testTraceparent+"|tenant=scalingo", string(body))
+	})
 }
 
 func TestNewClient_WithAuthentication(t *testing.T) {
@@ -0,0 +1,111 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Scalingo/go-utils/logger"
+)
+
+const (
+	requestIDHeader   = "X-Request-ID"
+	traceparentHeader = "traceparent"
+	baggageHeader     = "baggage"
+
+	// requestIDContextKey is the context key shared with the nsqproducer, cronsetup and httperrors modules to carry the
+	// request ID.
+	requestIDContextKey = "request_id"
+)
+
+var (
+	// propagator extracts the W3C Trace Context (traceparent, tracestate) and Baggage headers.
+	propagator propagation.TextMapPropagator = propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	)
+	traceContextPropagator = propagation.TraceContext{}
+	baggagePropagator      = propagation.Baggage{}
+)
+
+type MiddlewareOpt func(m *middleware)
+
+type middleware struct {
+	contextFields func(ctx context.Context, fields map[string]any) context.Context
+}
+
+// WithContextFields replaces the function called with the request_id and trace_id fields of the request. By default,
+// they are added to the logger of the context.
+func WithContextFields(contextFields func(ctx context.Context, fields map[string]any) context.Context) MiddlewareOpt {
+	return func(m *middleware) {
+		m.contextFields = contextFields
+	}
+}
+
+// Middleware is the server-side companion of the client returned by NewClient, created by NewMiddleware without option.
+func Middleware(next http.Handler) http.Handler {
+	return NewMiddleware()(next)
+}
+
+// NewMiddleware returns a middleware extracting the X-Request-ID header, the W3C Trace Context and the Baggage headers
+// from the incoming request and storing them in the request context.
+//
+// If the incoming request has no X-Request-ID header, a new UUID is generated. The request ID is stored in the context
+// under the "request_id" key (as expected by NewClient, nsqproducer and cronsetup) and sent back in the X-Request-ID
+// response header. The request_id and trace_id fields are added to the logger of the context.
+func NewMiddleware(opts ...MiddlewareOpt) func(http.Handler) http.Handler {
+	m := &middleware{contextFields: loggerContextFields}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			reqID := r.Header.Get(requestIDHeader)
+			if reqID == "" {
+				reqUUID, err := uuid.NewV4()
+				if err != nil {
+					http.Error(w, "fail to generate the request ID", http.StatusInternalServerError)
+					return
+				}
+				reqID = reqUUID.String()
+			}
+			ctx = ContextWithRequestID(ctx, reqID)
+
+			if m.contextFields != nil {
+				fields := map[string]any{"request_id": reqID}
+				spanContext := trace.SpanContextFromContext(ctx)
+				if spanContext.IsValid() {
+					fields["trace_id"] = spanContext.TraceID().String()
+				}
+				ctx = m.contextFields(ctx, fields)
+			}
+
+			w.Header().Set(requestIDHeader, reqID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// loggerContextFields adds the fields to the logger of the context
+func loggerContextFields(ctx context.Context, fields map[string]any) context.Context {
+	ctx, _ = logger.WithFieldsToCtx(ctx, logrus.Fields(fields))
+	return ctx
+}
+
+// ContextWithRequestID returns a copy of ctx carrying the given request ID under the "request_id" key.
+func ContextWithRequestID(ctx context.Context, reqID string) context.Context {
+	//nolint:revive,staticcheck // The "request_id" key is shared with other modules and repositories as a string.
+	return context.WithValue(ctx, requestIDContextKey, reqID)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx under the "request_id" key.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	reqID, ok := ctx.Value(requestIDContextKey).(string)
+	return reqID, ok
+}
@@ -0,0 +1,133 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Scalingo/go-utils/logger"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestMiddleware(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Headers map[string]string
+		Expect  func(*testing.T, *http.Request, *httptest.ResponseRecorder)
+	}{
+		{
+			Name:    "it should store the X-Request-ID header in the context",
+			Headers: map[string]string{"X-Request-ID": "123"},
+			Expect: func(t *testing.T, r *http.Request, res *httptest.ResponseRecorder) {
+				assert.Equal(t, "123", r.Context().Value("request_id"))
+				assert.Equal(t, "123", res.Header().Get("X-Request-ID"))
+			},
+		}, {
+			Name: "it should generate a request ID if none is present",
+			Expect: func(t *testing.T, r *http.Request, res *httptest.ResponseRecorder) {
+				reqID, ok := r.Context().Value("request_id").(string)
+				require.True(t, ok)
+				assert.Len(t, reqID, 36)
+				assert.Equal(t, reqID, res.Header().Get("X-Request-ID"))
+			},
+		}, {
+			Name: "it should add the request ID and trace ID to the logger of the context",
+			Headers: map[string]string{
+				"X-Request-ID": "123",
+				"traceparent":  testTraceparent,
+			},
+			Expect: func(t *testing.T, r *http.Request, _ *httptest.ResponseRecorder) {
+				entry, ok := logger.Get(r.Context()).(*logrus.Entry)
+				require.True(t, ok)
+				assert.Equal(t, "123", entry.Data["request_id"])
+				assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry.Data["trace_id"])
+			},
+		}, {
+			Name: "it should extract the W3C trace context and baggage",
+			Headers: map[string]string{
+				"traceparent": testTraceparent,
+				"baggage":     "tenant=scalingo",
+			},
+			Expect: func(t *testing.T, r *http.Request, _ *httptest.ResponseRecorder) {
+				spanContext := trace.SpanContextFromContext(r.Context())
+				assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spanContext.TraceID().String())
+				assert.True(t, spanContext.IsRemote())
+				assert.Equal(t, "scalingo", baggage.FromContext(r.Context()).Member("tenant").Value())
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var received *http.Request
+			handler := Middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				received = r
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range c.Headers {
+				req.Header.Set(k, v)
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			require.NotNil(t, received)
+			c.Expect(t, received, res)
+		})
+	}
+}
+
+func TestNewMiddleware_WithContextFields(t *testing.T) {
+	var fields map[string]any
+	handler := NewMiddleware(WithContextFields(func(ctx context.Context, f map[string]any) context.Context {
+		fields = f
+		return ctx
+	}))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-ID", "123")
+	req.Header.Set("traceparent", testTraceparent)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, map[string]any{"request_id": "123", "trace_id": "4bf92f3577b34da6a3ce929d0e0e4736"}, fields)
+}
+
+func TestMiddleware_NewClient(t *testing.T) {
+	t.Run("it should forward the request ID and trace context to downstream services", func(t *testing.T) {
+		downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s|%s|%s", r.Header.Get("X-Request-ID"), r.Header.Get("traceparent"), r.Header.Get("baggage"))
+		}))
+		defer downstream.Close()
+
+		var body string
+		handler := Middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, downstream.URL, nil)
+			require.NoError(t, err)
+			res, err := NewClient().Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			b, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			body = string(b)
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(context.Background())
+		req.Header.Set("X-Request-ID", "123")
+		req.Header.Set("traceparent", testTraceparent)
+		req.Header.Set("baggage", "tenant=scalingo")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, "123|"+testTraceparent+"|tenant=scalingo", body)
+	})
+}
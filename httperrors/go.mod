@@ -0,0 +1,28 @@
+module github.com/Scalingo/go-utils/httperrors
+
+go 1.25.0
+
+require (
+	github.com/Scalingo/go-utils/errors/v3 v3.3.0
+	github.com/Scalingo/go-utils/httpclient v1.3.0
+	github.com/Scalingo/go-utils/logger v1.12.2
+	github.com/stretchr/testify v1.11.1
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/gofrs/uuid/v5 v5.4.0 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/sirupsen/logrus v1.9.4 // indirect
+	go.opentelemetry.io/otel v1.45.0 // indirect
+	go.opentelemetry.io/otel/trace v1.45.0 // indirect
+	golang.org/x/sys v0.42.0 // indirect
+	gopkg.in/mgo.v2 v2.0.0-20190816093944-a6b53ec6cb22 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// replace github.com/Scalingo/go-utils/errors/v3 => ../errors
+
+// replace github.com/Scalingo/go-utils/httpclient => ../httpclient
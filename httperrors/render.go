@@ -0,0 +1,127 @@
+// Package httperrors renders the errors as JSON HTTP responses, with a status code matching the kind of the error (see
+// errors.KindOf).
+package httperrors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Scalingo/go-utils/errors/v3"
+	"github.com/Scalingo/go-utils/httpclient"
+	"github.com/Scalingo/go-utils/logger"
+)
+
+// Format is the JSON document written by the Renderer
+type Format int
+
+const (
+	// FormatProblemJSON writes a RFC 7807 problem details document with the application/problem+json content type
+	FormatProblemJSON Format = iota
+	// FormatLegacy writes {"errors": {"field": ["message"]}} for the validation errors and {"error": "message"} otherwise
+	FormatLegacy
+)
+
+// Problem is a RFC 7807 problem details document. RequestID and Errors are extension members.
+type Problem struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail,omitempty"`
+	RequestID string              `json:"request_id,omitempty"`
+	Errors    map[string][]string `json:"errors,omitempty"`
+}
+
+type legacyError struct {
+	Error     string              `json:"error,omitempty"`
+	Errors    map[string][]string `json:"errors,omitempty"`
+	RequestID string              `json:"request_id,omitempty"`
+}
+
+type Renderer struct {
+	format Format
+}
+
+type Option func(*Renderer)
+
+// WithFormat sets the format of the documents (default: FormatProblemJSON)
+func WithFormat(format Format) Option {
+	return func(r *Renderer) {
+		r.format = format
+	}
+}
+
+func NewRenderer(opts ...Option) *Renderer {
+	r := &Renderer{format: FormatProblemJSON}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+var defaultRenderer = NewRenderer()
+
+// Render writes err with the default renderer
+func Render(ctx context.Context, w http.ResponseWriter, err error) {
+	defaultRenderer.Render(ctx, w, err)
+}
+
+// Render logs err with the logger of the deepest context of the error chain and writes it to w. The message of the
+// errors with a 5xx status code is replaced by the status text not to disclose internal details. A nil error is
+// rendered as an internal error.
+func (r *Renderer) Render(ctx context.Context, w http.ResponseWriter, err error) {
+	if err == nil {
+		err = errors.New(ctx, "render a nil error")
+	}
+	rootCtx := errors.RootCtxOrFallback(ctx, err)
+	status := errors.HTTPStatus(err)
+
+	log := logger.Get(rootCtx).WithError(err)
+	if status >= http.StatusInternalServerError {
+		log.Error("Request failed")
+	} else {
+		log.Info("Request failed")
+	}
+
+	detail := err.Error()
+	if status >= http.StatusInternalServerError {
+		detail = http.StatusText(status)
+	}
+	requestID, ok := httpclient.RequestIDFromContext(rootCtx)
+	if !ok {
+		requestID, _ = httpclient.RequestIDFromContext(ctx)
+	}
+	var validationErrors map[string][]string
+	var validationErr *errors.ValidationErrors
+	if errors.As(err, &validationErr) {
+		validationErrors = validationErr.Errors
+	}
+
+	var document any
+	contentType := "application/problem+json"
+	switch r.format {
+	case FormatLegacy:
+		contentType = "application/json"
+		legacy := legacyError{Errors: validationErrors, RequestID: requestID}
+		if validationErrors == nil {
+			legacy.Error = detail
+		}
+		document = legacy
+	default:
+		document = Problem{
+			Type:      "about:blank",
+			Title:     http.StatusText(status),
+			Status:    status,
+			Detail:    detail,
+			RequestID: requestID,
+			Errors:    validationErrors,
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(document)
+	if err != nil {
+		logger.Get(rootCtx).WithError(err).Error("Fail to encode the error response")
+	}
+}
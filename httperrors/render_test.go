@@ -0,0 +1,89 @@
+package httperrors
+
+import (
+	"context"
+	stdErrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Scalingo/go-utils/errors/v3"
+	"github.com/Scalingo/go-utils/httpclient"
+)
+
+func TestRenderer_Render(t *testing.T) {
+	ctx := httpclient.ContextWithRequestID(context.Background(), "request-1")
+	validationErr := errors.NewValidationErrorsBuilder().Set("name", "is empty").Build()
+
+	tests := map[string]struct {
+		format              Format
+		err                 error
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		"an error with a kind": {
+			err:                 errors.Wrap(ctx, errors.WithKind(stdErrors.New("app not found"), errors.KindNotFound), "get app"),
+			expectedStatus:      http.StatusNotFound,
+			expectedContentType: "application/problem+json",
+			expectedBody:        `{"type":"about:blank","title":"Not Found","status":404,"detail":"get app: app not found","request_id":"request-1"}`,
+		},
+		"an internal error hides the message": {
+			err:                 errors.Wrap(ctx, stdErrors.New("connection refused"), "get app"),
+			expectedStatus:      http.StatusInternalServerError,
+			expectedContentType: "application/problem+json",
+			expectedBody:        `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"Internal Server Error","request_id":"request-1"}`,
+		},
+		"validation errors": {
+			err:                 errors.Wrap(ctx, validationErr, "validate app"),
+			expectedStatus:      http.StatusUnprocessableEntity,
+			expectedContentType: "application/problem+json",
+			expectedBody:        `{"type":"about:blank","title":"Unprocessable Entity","status":422,"detail":"validate app: name=is empty","request_id":"request-1","errors":{"name":["is empty"]}}`,
+		},
+		"validation errors with the legacy format": {
+			format:              FormatLegacy,
+			err:                 errors.Wrap(ctx, validationErr, "validate app"),
+			expectedStatus:      http.StatusUnprocessableEntity,
+			expectedContentType: "application/json",
+			expectedBody:        `{"errors":{"name":["is empty"]},"request_id":"request-1"}`,
+		},
+		"an error with the legacy format": {
+			format:              FormatLegacy,
+			err:                 errors.WithKind(errors.New(ctx, "app already exists"), errors.KindConflict),
+			expectedStatus:      http.StatusConflict,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"app already exists","request_id":"request-1"}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			NewRenderer(WithFormat(test.format)).Render(context.Background(), recorder, test.err)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedContentType, recorder.Header().Get("Content-Type"))
+			assert.JSONEq(t, test.expectedBody, recorder.Body.String())
+		})
+	}
+
+	t.Run("it uses the request ID of the given context if the error has no context", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		Render(ctx, recorder, stdErrors.New("connection refused"))
+
+		assert.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"Internal Server Error","request_id":"request-1"}`, recorder.Body.String())
+	})
+
+	t.Run("it renders a nil error as an internal error", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		Render(ctx, recorder, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		assert.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"Internal Server Error","request_id":"request-1"}`, recorder.Body.String())
+	})
+}
@@ -0,0 +1,329 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultAsyncOutputBufferSize = 1024
+	// asyncOutputExitFlushTimeout is the maximum duration the fatal entries wait to be written before exiting
+	asyncOutputExitFlushTimeout = 5 * time.Second
+)
+
+// AsyncOutputPolicy is the behavior of an asynchronous output when its buffer is full
+type AsyncOutputPolicy int
+
+const (
+	// AsyncOutputDropOldest drops the oldest buffered entry to buffer the new one
+	AsyncOutputDropOldest AsyncOutputPolicy = iota
+	// AsyncOutputDropNewest drops the new entry
+	AsyncOutputDropNewest
+	// AsyncOutputBlock blocks the logging goroutine until an entry is written
+	AsyncOutputBlock
+)
+
+// AsyncOutputConfig configures the asynchronous output of the logger
+type AsyncOutputConfig struct {
+	// BufferSize is the maximum number of buffered entries (default: 1024)
+	BufferSize int
+	Policy     AsyncOutputPolicy
+}
+
+var asyncOutputs = &asyncOutputRegistry{
+	writers:       map[io.Writer]*asyncWriter{},
+	unsharedTypes: map[reflect.Type]struct{}{},
+}
+
+// WithAsyncOutput buffers the entries and writes them to the current output of the logger from a background
+// goroutine, so that logging does not block when the output stalls. It must be set after WithOutput. The loggers
+// writing to the same output share the same buffer and goroutine, configured by the first WithAsyncOutput: the
+// configuration of the next ones is ignored. The outputs which are not comparable (e.g. a struct value holding a slice)
+// can't be shared and are kept synchronous.
+//
+// CloseAsyncOutputs must be called before exiting not to lose the buffered entries, including when panicking (e.g.
+// deferred in main). The fatal entries are flushed by a logrus exit handler.
+func WithAsyncOutput(config AsyncOutputConfig) Opt {
+	return func(l *logrus.Logger) {
+		if _, ok := l.Out.(*asyncWriter); ok {
+			return
+		}
+		l.SetOutput(asyncOutputs.get(l.Out, config))
+	}
+}
+
+// AsyncOutputDroppedEntries returns the number of entries dropped by the asynchronous outputs because their buffer
+// was full
+func AsyncOutputDroppedEntries() uint64 {
+	return asyncOutputs.dropped()
+}
+
+// FlushAsyncOutputs waits until the entries buffered by the asynchronous outputs are written. Its signature matches
+// the shutdown hooks of graceful.Service.
+func FlushAsyncOutputs(ctx context.Context) error {
+	return asyncOutputs.flush(ctx)
+}
+
+// CloseAsyncOutputs flushes the asynchronous outputs and stops their goroutines. The loggers keep working: their
+// entries are then written synchronously, until a new WithAsyncOutput. Its signature matches the shutdown hooks of
+// graceful.Service.
+func CloseAsyncOutputs(ctx context.Context) error {
+	return asyncOutputs.close(ctx)
+}
+
+type asyncOutputRegistry struct {
+	lock    sync.Mutex
+	writers map[io.Writer]*asyncWriter
+	// closedDropped is the number of entries dropped by the closed writers
+	closedDropped atomic.Uint64
+	// unsharedTypes are the types of the outputs which were kept synchronous, to only warn once
+	unsharedTypes map[reflect.Type]struct{}
+
+	exitHandlerOnce sync.Once
+}
+
+// get returns the asynchronous writer of the output. The outputs are keyed by value, hence by identity for the
+// pointers. The non comparable outputs are returned as is: a new writer for each call would leak its goroutine.
+func (r *asyncOutputRegistry) get(output io.Writer, config AsyncOutputConfig) io.Writer {
+	r.exitHandlerOnce.Do(func() {
+		logrus.RegisterExitHandler(r.flushBeforeExit)
+	})
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	config = config.withDefaults()
+	outputType := reflect.TypeOf(output)
+	if !outputType.Comparable() {
+		if _, ok := r.unsharedTypes[outputType]; !ok {
+			r.unsharedTypes[outputType] = struct{}{}
+			fmt.Fprintf(os.Stderr, "The log output %v is not comparable, it is kept synchronous\n", outputType)
+		}
+		return output
+	}
+	w, ok := r.writers[output]
+	if !ok {
+		w = newAsyncWriter(output, config)
+		r.writers[output] = w
+	} else if w.config != config {
+		fmt.Fprintf(os.Stderr, "The async log output is already configured with %+v, ignoring %+v\n", w.config, config)
+	}
+	return w
+}
+
+// flushBeforeExit writes the buffered entries, including the fatal one, before logrus exits
+func (r *asyncOutputRegistry) flushBeforeExit() {
+	ctx, cancel := context.WithTimeout(context.Background(), asyncOutputExitFlushTimeout)
+	defer cancel()
+
+	err := r.flush(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to flush the log output before exiting, %v\n", err)
+	}
+}
+
+func (r *asyncOutputRegistry) all() []*asyncWriter {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	writers := make([]*asyncWriter, 0, len(r.writers))
+	for _, w := range r.writers {
+		writers = append(writers, w)
+	}
+	return writers
+}
+
+func (r *asyncOutputRegistry) dropped() uint64 {
+	dropped := r.closedDropped.Load()
+	for _, w := range r.all() {
+		dropped += w.dropped.Load()
+	}
+	return dropped
+}
+
+func (r *asyncOutputRegistry) flush(ctx context.Context) error {
+	for _, w := range r.all() {
+		err := w.flush(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close removes the writers from the registry before closing them, so that a new WithAsyncOutput starts a new writer
+func (r *asyncOutputRegistry) close(ctx context.Context) error {
+	r.lock.Lock()
+	writers := make([]*asyncWriter, 0, len(r.writers))
+	for output, w := range r.writers {
+		writers = append(writers, w)
+		delete(r.writers, output)
+	}
+	r.lock.Unlock()
+
+	var errs []error
+	for _, w := range writers {
+		err := w.close(ctx)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		r.closedDropped.Add(w.dropped.Load())
+	}
+	return errors.Join(errs...)
+}
+
+func (c AsyncOutputConfig) withDefaults() AsyncOutputConfig {
+	if c.BufferSize <= 0 {
+		c.BufferSize = defaultAsyncOutputBufferSize
+	}
+	return c
+}
+
+// asyncWriter buffers the written entries in a ring and writes them to the output from a background goroutine
+type asyncWriter struct {
+	output io.Writer
+	config AsyncOutputConfig
+
+	lock  sync.Mutex
+	cond  *sync.Cond
+	ring  [][]byte
+	head  int
+	count int
+	// writing is true while the goroutine writes entries which are no longer in the ring
+	writing bool
+	// closed stops the goroutine once the ring is empty. The next entries are written synchronously.
+	closed bool
+
+	dropped atomic.Uint64
+}
+
+func newAsyncWriter(output io.Writer, config AsyncOutputConfig) *asyncWriter {
+	w := &asyncWriter{
+		output: output,
+		config: config,
+		ring:   make([][]byte, config.BufferSize),
+	}
+	w.cond = sync.NewCond(&w.lock)
+	go w.run()
+	return w
+}
+
+// Write buffers a copy of the entry as logrus reuses its buffers
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	w.lock.Lock()
+	buffered := w.buffer(append([]byte(nil), p...))
+	w.lock.Unlock()
+	if !buffered {
+		return w.output.Write(p)
+	}
+	return len(p), nil
+}
+
+// buffer adds the entry to the ring according to the policy. It returns false if the writer is closed, the entry
+// must then be written synchronously. The lock must be held.
+func (w *asyncWriter) buffer(entry []byte) bool {
+	if w.count == len(w.ring) && !w.closed {
+		switch w.config.Policy {
+		case AsyncOutputDropNewest:
+			w.dropped.Add(1)
+			return true
+		case AsyncOutputBlock:
+			for w.count == len(w.ring) && !w.closed {
+				w.cond.Wait()
+			}
+		default:
+			w.ring[w.head] = nil
+			w.head = (w.head + 1) % len(w.ring)
+			w.count--
+			w.dropped.Add(1)
+		}
+	}
+	if w.closed {
+		return false
+	}
+
+	w.ring[(w.head+w.count)%len(w.ring)] = entry
+	w.count++
+	w.cond.Broadcast()
+	return true
+}
+
+func (w *asyncWriter) run() {
+	for {
+		w.lock.Lock()
+		for w.count == 0 && !w.closed {
+			w.cond.Wait()
+		}
+		if w.count == 0 {
+			w.lock.Unlock()
+			return
+		}
+		entries := make([][]byte, 0, w.count)
+		for w.count > 0 {
+			entries = append(entries, w.ring[w.head])
+			w.ring[w.head] = nil
+			w.head = (w.head + 1) % len(w.ring)
+			w.count--
+		}
+		w.writing = true
+		w.cond.Broadcast()
+		w.lock.Unlock()
+
+		for _, entry := range entries {
+			_, err := w.output.Write(entry)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to write to log, %v\n", err)
+			}
+		}
+
+		w.lock.Lock()
+		w.writing = false
+		w.cond.Broadcast()
+		w.lock.Unlock()
+	}
+}
+
+func (w *asyncWriter) flush(ctx context.Context) error {
+	// Wake up the waiting loop when the context is done
+	stop := context.AfterFunc(ctx, func() {
+		w.lock.Lock()
+		defer w.lock.Unlock()
+		w.cond.Broadcast()
+	})
+	defer stop()
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	for w.count > 0 || w.writing {
+		if ctx.Err() != nil {
+			return fmt.Errorf("flush the async log output: %w", ctx.Err())
+		}
+		w.cond.Wait()
+	}
+	return nil
+}
+
+// close flushes the buffered entries and stops the goroutine. The goroutine still writes the remaining entries if the
+// flush is canceled.
+func (w *asyncWriter) close(ctx context.Context) error {
+	err := w.flush(ctx)
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.closed = true
+	w.cond.Broadcast()
+	return err
+}
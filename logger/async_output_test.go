@@ -0,0 +1,225 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedWriter blocks the writes until the gate is opened
+type gatedWriter struct {
+	started     chan struct{}
+	startedOnce sync.Once
+	gate        chan struct{}
+
+	lock   sync.Mutex
+	output strings.Builder
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{started: make(chan struct{}), gate: make(chan struct{})}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	w.startedOnce.Do(func() { close(w.started) })
+	<-w.gate
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.output.Write(p)
+}
+
+func (w *gatedWriter) String() string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.output.String()
+}
+
+func TestWithAsyncOutput(t *testing.T) {
+	newAsyncLogger := func(t *testing.T, output *gatedWriter, config AsyncOutputConfig) (*logrus.Logger, *asyncWriter) {
+		t.Helper()
+
+		log := defaultLogrusLogger(t,
+			WithOutput(output),
+			WithLogFormatter(&logrus.TextFormatter{DisableTimestamp: true}),
+			WithAsyncOutput(config),
+		)
+		writer, ok := log.Out.(*asyncWriter)
+		require.True(t, ok)
+		return log, writer
+	}
+
+	t.Run("it does not block while the output stalls and flushes the entries", func(t *testing.T) {
+		output := newGatedWriter()
+		log, _ := newAsyncLogger(t, output, AsyncOutputConfig{})
+
+		log.Info("first")
+		log.Info("second")
+		assert.Empty(t, output.String())
+
+		close(output.gate)
+		require.NoError(t, FlushAsyncOutputs(t.Context()))
+
+		assert.Equal(t, "level=info msg=first\nlevel=info msg=second\n", output.String())
+	})
+
+	tests := map[string]struct {
+		policy          AsyncOutputPolicy
+		expectedOutput  string
+		expectedDropped uint64
+	}{
+		"drop oldest": {
+			policy:          AsyncOutputDropOldest,
+			expectedOutput:  "level=info msg=1\nlevel=info msg=3\nlevel=info msg=4\n",
+			expectedDropped: 1,
+		},
+		"drop newest": {
+			policy:          AsyncOutputDropNewest,
+			expectedOutput:  "level=info msg=1\nlevel=info msg=2\nlevel=info msg=3\n",
+			expectedDropped: 1,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			output := newGatedWriter()
+			log, writer := newAsyncLogger(t, output, AsyncOutputConfig{BufferSize: 2, Policy: test.policy})
+
+			log.Info("1")
+			// The first entry is out of the buffer once its write started
+			<-output.started
+			log.Info("2")
+			log.Info("3")
+			log.Info("4")
+
+			close(output.gate)
+			require.NoError(t, FlushAsyncOutputs(t.Context()))
+
+			assert.Equal(t, test.expectedOutput, output.String())
+			assert.Equal(t, test.expectedDropped, writer.dropped.Load())
+			assert.GreaterOrEqual(t, AsyncOutputDroppedEntries(), test.expectedDropped)
+		})
+	}
+
+	t.Run("block waits for space in the buffer", func(t *testing.T) {
+		output := newGatedWriter()
+		log, writer := newAsyncLogger(t, output, AsyncOutputConfig{BufferSize: 1, Policy: AsyncOutputBlock})
+
+		log.Info("1")
+		<-output.started
+		log.Info("2")
+
+		logged := make(chan struct{})
+		go func() {
+			log.Info("3")
+			close(logged)
+		}()
+		select {
+		case <-logged:
+			t.Fatal("the entry should wait for space in the buffer")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		close(output.gate)
+		<-logged
+		require.NoError(t, FlushAsyncOutputs(t.Context()))
+
+		assert.Equal(t, "level=info msg=1\nlevel=info msg=2\nlevel=info msg=3\n", output.String())
+		assert.Zero(t, writer.dropped.Load())
+	})
+
+	t.Run("the loggers writing to the same output share the buffer", func(t *testing.T) {
+		output := newGatedWriter()
+		close(output.gate)
+		_, writer1 := newAsyncLogger(t, output, AsyncOutputConfig{})
+		_, writer2 := newAsyncLogger(t, output, AsyncOutputConfig{})
+
+		assert.Same(t, writer1, writer2)
+	})
+
+	t.Run("flush returns an error when the context is canceled", func(t *testing.T) {
+		output := newGatedWriter()
+		log, _ := newAsyncLogger(t, output, AsyncOutputConfig{})
+		t.Cleanup(func() { close(output.gate) })
+
+		log.Info("stalled")
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+
+		require.ErrorIs(t, FlushAsyncOutputs(ctx), context.DeadlineExceeded)
+	})
+
+	t.Run("it writes the fatal entries before exiting", func(t *testing.T) {
+		output := newGatedWriter()
+		log, _ := newAsyncLogger(t, output, AsyncOutputConfig{})
+		exitCode := -1
+		log.ExitFunc = func(code int) { exitCode = code }
+		go func() {
+			<-output.started
+			close(output.gate)
+		}()
+
+		log.Fatal("fatal")
+
+		assert.Equal(t, 1, exitCode)
+		assert.Equal(t, "level=fatal msg=fatal\n", output.String())
+	})
+
+	t.Run("the first configuration of an output is kept", func(t *testing.T) {
+		output := newGatedWriter()
+		close(output.gate)
+		_, writer := newAsyncLogger(t, output, AsyncOutputConfig{BufferSize: 2})
+		_, _ = newAsyncLogger(t, output, AsyncOutputConfig{BufferSize: 4, Policy: AsyncOutputBlock})
+
+		assert.Equal(t, AsyncOutputConfig{BufferSize: 2, Policy: AsyncOutputDropOldest}, writer.config)
+	})
+	t.Run("it skips the empty writes", func(t *testing.T) {
+		output := newGatedWriter()
+		_, writer := newAsyncLogger(t, output, AsyncOutputConfig{})
+
+		n, err := writer.Write(nil)
+
+		require.NoError(t, err)
+		assert.Zero(t, n)
+		writer.lock.Lock()
+		defer writer.lock.Unlock()
+		assert.Zero(t, writer.count)
+	})
+
+	t.Run("close writes the buffered entries and the next ones synchronously", func(t *testing.T) {
+		output := newGatedWriter()
+		log, writer := newAsyncLogger(t, output, AsyncOutputConfig{})
+
+		log.Info("buffered")
+		close(output.gate)
+		require.NoError(t, CloseAsyncOutputs(t.Context()))
+		log.Info("synchronous")
+
+		assert.Equal(t, "level=info msg=buffered\nlevel=info msg=synchronous\n", output.String())
+		_, newWriter := newAsyncLogger(t, output, AsyncOutputConfig{})
+		assert.NotSame(t, writer, newWriter)
+	})
+
+	t.Run("the non comparable outputs are kept synchronous", func(t *testing.T) {
+		output := nonComparableWriter{output: &strings.Builder{}}
+
+		log := defaultLogrusLogger(t, WithOutput(output), WithAsyncOutput(AsyncOutputConfig{}))
+
+		assert.IsType(t, nonComparableWriter{}, log.Out)
+	})
+}
+
+// nonComparableWriter can't be a map key because of its slice
+type nonComparableWriter struct {
+	output *strings.Builder
+	_      []byte
+}
+
+func (w nonComparableWriter) Write(p []byte) (int, error) {
+	return w.output.Write(p)
+}
@@ -1,11 +1,20 @@
 package logger
 
 import (
+	"io"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+// ComponentField is the field identifying the component which logs an entry. The level of a component can be
+// overridden with the LOGGER_LEVELS environment variable.
+const ComponentField = "component"
+
 var defaultLogLevel logrus.Level = logrus.InfoLevel
 var defaultTextFormatter logrus.Formatter = &logrus.TextFormatter{
 	TimestampFormat: "2006-01-02T15:04:05.000",
@@ -16,6 +25,14 @@ func formatter() logrus.Formatter {
 	switch os.Getenv("LOGGER_TYPE") {
 	case "json":
 		return new(logrus.JSONFormatter)
+	case "logfmt":
+		return &logrus.TextFormatter{
+			DisableColors:   true,
+			FullTimestamp:   true,
+			TimestampFormat: time.RFC3339Nano,
+		}
+	case "ecs":
+		return &ECSFormatter{}
 	case "text":
 		return defaultTextFormatter
 	default:
@@ -24,18 +41,134 @@ func formatter() logrus.Formatter {
 }
 
 func logLevel() logrus.Level {
-	switch os.Getenv("LOGGER_LEVEL") {
-	case "panic":
-		return logrus.PanicLevel
-	case "fatal":
-		return logrus.FatalLevel
-	case "warn":
-		return logrus.WarnLevel
-	case "info":
-		return logrus.InfoLevel
-	case "debug":
-		return logrus.DebugLevel
-	default:
+	level, err := logrus.ParseLevel(os.Getenv("LOGGER_LEVEL"))
+	if err != nil {
 		return defaultLogLevel
 	}
+	return level
+}
+
+// reportCaller returns whether the calling method is added to the entries, from the LOGGER_REPORT_CALLER
+// environment variable
+func reportCaller() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("LOGGER_REPORT_CALLER"))
+	return enabled
+}
+
+// errorStack returns whether the stack traces of the errors are added to the entries, from the ERRORS_STACK_TRACE
+// environment variable which enables their capture in github.com/Scalingo/go-utils/errors
+func errorStack() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("ERRORS_STACK_TRACE"))
+	return enabled
+}
+
+// componentLevels parses the LOGGER_LEVELS environment variable, e.g. "nsqconsumer=debug,cron=warn". Invalid
+// overrides are ignored.
+func componentLevels() map[string]logrus.Level {
+	levels := map[string]logrus.Level{}
+	for _, override := range strings.Split(os.Getenv("LOGGER_LEVELS"), ",") {
+		component, levelName, ok := strings.Cut(strings.TrimSpace(override), "=")
+		if !ok || component == "" {
+			continue
+		}
+		level, err := logrus.ParseLevel(levelName)
+		if err != nil {
+			continue
+		}
+		levels[component] = level
+	}
+	return levels
+}
+
+// withComponentLevels keeps the level overrides of the components, applied to the loggers created by WithComponent.
+// The output of the logger is shared with the loggers of the components.
+func withComponentLevels(l *logrus.Logger, levels map[string]logrus.Level) {
+	if len(levels) == 0 {
+		return
+	}
+
+	output := &sharedOutput{Writer: l.Out}
+	l.SetOutput(output)
+	l.SetFormatter(&componentLevelFormatter{
+		Formatter: l.Formatter,
+		levels:    levels,
+		output:    output,
+		loggers:   map[string]*logrus.Logger{},
+	})
+}
+
+// WithComponent adds the component field to the logger. If the level of the component is overridden with
+// LOGGER_LEVELS, the returned logger has the level of the component: the entries which are not enabled for the
+// component are dropped before the hooks are fired, and IsLevelEnabled reflects the level of the component. As the
+// level of the other loggers, it is changed by SetLevel and restored by ResetLevel.
+func WithComponent(log logrus.FieldLogger, component string) logrus.FieldLogger {
+	entry := log.WithField(ComponentField, component)
+	f, ok := entry.Logger.Formatter.(*componentLevelFormatter)
+	if !ok {
+		return entry
+	}
+	componentLogger := f.logger(entry.Logger, component)
+	if componentLogger == nil {
+		return entry
+	}
+
+	componentEntry := logrus.NewEntry(componentLogger).WithContext(entry.Context).WithFields(entry.Data)
+	if !entry.Time.IsZero() {
+		componentEntry = componentEntry.WithTime(entry.Time)
+	}
+	return componentEntry
+}
+
+// componentLevelFormatter carries the level overrides of the components and the loggers created for them. It formats
+// the entries with the wrapped formatter.
+type componentLevelFormatter struct {
+	logrus.Formatter
+	levels map[string]logrus.Level
+	output *sharedOutput
+
+	lock    sync.Mutex
+	loggers map[string]*logrus.Logger
+}
+
+// logger returns the logger of the component, sharing the output, formatter and hooks of l, or nil if the level of
+// the component is not overridden. The logger is registered to change its level at runtime.
+func (f *componentLevelFormatter) logger(l *logrus.Logger, component string) *logrus.Logger {
+	level, ok := f.levels[component]
+	if !ok {
+		return nil
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	componentLogger, ok := f.loggers[component]
+	if ok {
+		return componentLogger
+	}
+	componentLogger = &logrus.Logger{
+		Out:          f.output,
+		Hooks:        l.Hooks,
+		Formatter:    f,
+		ReportCaller: l.ReportCaller,
+		Level:        level,
+		ExitFunc:     l.ExitFunc,
+		BufferPool:   l.BufferPool,
+	}
+	f.loggers[component] = componentLogger
+	levels.register(componentLogger)
+	return componentLogger
+}
+
+// sharedOutput serializes the writes of a logger and of the loggers of its components to the same output
+type sharedOutput struct {
+	io.Writer
+
+	lock sync.Mutex
+}
+
+func (o *sharedOutput) Write(p []byte) (int, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	return o.Writer.Write(p)
 }
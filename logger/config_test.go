@@ -1,11 +1,16 @@
 package logger
 
 import (
-	"os"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFormatter(t *testing.T) {
@@ -25,13 +30,21 @@ func TestFormatter(t *testing.T) {
 			Name:          "with a json type",
 			Env:           map[string]string{"LOGGER_TYPE": "json"},
 			FormatterType: &logrus.JSONFormatter{},
+		}, {
+			Name:          "with a logfmt type",
+			Env:           map[string]string{"LOGGER_TYPE": "logfmt"},
+			FormatterType: &logrus.TextFormatter{},
+		}, {
+			Name:          "with an ecs type",
+			Env:           map[string]string{"LOGGER_TYPE": "ecs"},
+			FormatterType: &ECSFormatter{},
 		},
 	}
 
 	for _, example := range examples {
 		t.Run(example.Name, func(t *testing.T) {
 			for k, v := range example.Env {
-				os.Setenv(k, v)
+				t.Setenv(k, v)
 			}
 
 			assert.IsType(t, example.FormatterType, formatter())
@@ -43,9 +56,12 @@ func TestLogLevel(t *testing.T) {
 	examples := map[string]logrus.Level{
 		"panic":   logrus.PanicLevel,
 		"fatal":   logrus.FatalLevel,
+		"error":   logrus.ErrorLevel,
 		"warn":    logrus.WarnLevel,
+		"warning": logrus.WarnLevel,
 		"info":    logrus.InfoLevel,
 		"debug":   logrus.DebugLevel,
+		"trace":   logrus.TraceLevel,
 		"":        logrus.InfoLevel,
 		"invalid": logrus.InfoLevel,
 	}
@@ -57,3 +73,85 @@ func TestLogLevel(t *testing.T) {
 		})
 	}
 }
+
+func TestComponentLevels(t *testing.T) {
+	t.Setenv("LOGGER_LEVELS", "nsqconsumer=debug, cron=warn,invalid,broken=unknown")
+
+	assert.Equal(t, map[string]logrus.Level{
+		"nsqconsumer": logrus.DebugLevel,
+		"cron":        logrus.WarnLevel,
+	}, componentLevels())
+}
+
+func TestDefault_ComponentLevels(t *testing.T) {
+	t.Setenv("LOGGER_LEVEL", "info")
+	t.Setenv("LOGGER_LEVELS", "nsqconsumer=debug,cron=warn")
+
+	var output bytes.Buffer
+	hook := test.NewLocal(logrus.New())
+	log := Default(WithOutput(&output), WithLogFormatter(&logrus.TextFormatter{DisableTimestamp: true}), WithHooks([]logrus.Hook{hook}))
+	nsqconsumerLog := WithComponent(log.WithField("app", "my-app"), "nsqconsumer")
+	cronLog := WithComponent(log, "cron")
+
+	log.Debug("default debug")
+	log.Info("default info")
+	nsqconsumerLog.Debug("nsqconsumer debug")
+	cronLog.Info("cron info")
+	cronLog.Warn("cron warn")
+	WithComponent(log, "other").Debug("other debug")
+
+	assert.NotContains(t, output.String(), "default debug")
+	assert.Contains(t, output.String(), "default info")
+	assert.Contains(t, output.String(), "nsqconsumer debug")
+	assert.Contains(t, output.String(), "app=my-app component=nsqconsumer")
+	assert.NotContains(t, output.String(), "cron info")
+	assert.Contains(t, output.String(), "cron warn")
+	assert.NotContains(t, output.String(), "other debug")
+
+	// The hooks only receive the entries enabled for their component
+	var messages []string
+	for _, entry := range hook.AllEntries() {
+		messages = append(messages, entry.Message)
+	}
+	assert.Equal(t, []string{"default info", "nsqconsumer debug", "cron warn"}, messages)
+
+	assert.False(t, log.(*logrus.Logger).IsLevelEnabled(logrus.DebugLevel))
+	assert.True(t, nsqconsumerLog.(*logrus.Entry).Logger.IsLevelEnabled(logrus.DebugLevel))
+	assert.False(t, cronLog.(*logrus.Entry).Logger.IsLevelEnabled(logrus.InfoLevel))
+}
+
+func TestDefault_ReportCaller(t *testing.T) {
+	t.Setenv("LOGGER_REPORT_CALLER", "true")
+
+	var output bytes.Buffer
+	log := Default(WithOutput(&output), WithLogFormatter(&logrus.JSONFormatter{}))
+	log.Info("test")
+
+	assert.Contains(t, output.String(), `"func":"github.com/Scalingo/go-utils/logger.TestDefault_ReportCaller"`)
+}
+
+func TestECSFormatter(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New()).WithFields(logrus.Fields{
+		"app":           "my-app",
+		logrus.ErrorKey: errors.New("deployment failed"),
+	})
+	entry.Level = logrus.WarnLevel
+	entry.Message = "Deployment error"
+	entry.Time = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	serialized, err := (&ECSFormatter{}).Format(entry)
+	require.NoError(t, err)
+
+	var document map[string]any
+	require.NoError(t, json.Unmarshal(serialized, &document))
+	assert.Equal(t, map[string]any{
+		"@timestamp":    "2024-01-02T03:04:05Z",
+		"message":       "Deployment error",
+		"log.level":     "warning",
+		"ecs.version":   ecsVersion,
+		"app":           "my-app",
+		"error.message": "deployment failed",
+	}, document)
+	// The entry itself is not modified
+	assert.Contains(t, entry.Data, logrus.ErrorKey)
+}
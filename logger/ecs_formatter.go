@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ecsVersion is the version of the Elastic Common Schema the entries comply with
+const ecsVersion = "8.11.0"
+
+// ECSFormatter formats the entries as JSON documents following the Elastic Common Schema
+// https://www.elastic.co/guide/en/ecs/current/ecs-base.html
+type ECSFormatter struct{}
+
+var ecsJSONFormatter = &logrus.JSONFormatter{
+	TimestampFormat: time.RFC3339Nano,
+	FieldMap: logrus.FieldMap{
+		logrus.FieldKeyTime:  "@timestamp",
+		logrus.FieldKeyMsg:   "message",
+		logrus.FieldKeyLevel: "log.level",
+		logrus.FieldKeyFunc:  "log.origin.function",
+		logrus.FieldKeyFile:  "log.origin.file.name",
+	},
+}
+
+func (f *ECSFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data := make(logrus.Fields, len(entry.Data)+1)
+	for key, value := range entry.Data {
+		data[key] = value
+	}
+	data["ecs.version"] = ecsVersion
+	if err, ok := data[logrus.ErrorKey].(error); ok {
+		delete(data, logrus.ErrorKey)
+		data["error.message"] = err.Error()
+	}
+
+	// The entry is copied so that the other hooks and formatters are not affected
+	ecsEntry := *entry
+	ecsEntry.Data = data
+	return ecsJSONFormatter.Format(&ecsEntry)
+}
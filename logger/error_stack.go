@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrorStackField is the field containing the stack trace of the error of the entry, when it has been captured (see
+// errors.EnableStackTrace of github.com/Scalingo/go-utils/errors)
+const ErrorStackField = "error_stack"
+
+type stackTracer interface {
+	StackTrace() []runtime.Frame
+}
+
+// WithErrorStack adds the stack trace of the errors to the ErrorStackField field of the entries. It is enabled by
+// default if ERRORS_STACK_TRACE is true, this option is only needed when the capture is enabled with
+// errors.EnableStackTrace.
+func WithErrorStack() Opt {
+	return func(l *logrus.Logger) {
+		for _, hook := range l.Hooks[logrus.InfoLevel] {
+			if _, ok := hook.(errorStackHook); ok {
+				return
+			}
+		}
+		l.Hooks.Add(errorStackHook{})
+	}
+}
+
+// errorStackHook adds the stack trace of the error field of the entries
+type errorStackHook struct{}
+
+func (h errorStackHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h errorStackHook) Fire(entry *logrus.Entry) error {
+	err, ok := entry.Data[logrus.ErrorKey].(error)
+	if !ok || err == nil {
+		return nil
+	}
+	if _, ok := entry.Data[ErrorStackField]; ok {
+		return nil
+	}
+
+	var tracer stackTracer
+	if !errors.As(err, &tracer) {
+		return nil
+	}
+	frames := tracer.StackTrace()
+	if len(frames) == 0 {
+		return nil
+	}
+
+	stack := make([]string, 0, len(frames))
+	for _, frame := range frames {
+		stack = append(stack, fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line))
+	}
+	entry.Data[ErrorStackField] = stack
+	return nil
+}
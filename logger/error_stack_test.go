@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stackTracedError struct {
+	frames []runtime.Frame
+}
+
+func (err stackTracedError) Error() string {
+	return "stack traced error"
+}
+
+func (err stackTracedError) StackTrace() []runtime.Frame {
+	return err.frames
+}
+
+func TestErrorStackHook(t *testing.T) {
+	frames := []runtime.Frame{
+		{Function: "github.com/Scalingo/app.fetch", File: "/src/app/fetch.go", Line: 12},
+		{Function: "github.com/Scalingo/app.main", File: "/src/app/main.go", Line: 5},
+	}
+
+	tests := map[string]struct {
+		err           error
+		expectedStack any
+	}{
+		"an error with a stack trace": {
+			err:           stackTracedError{frames: frames},
+			expectedStack: []string{"github.com/Scalingo/app.fetch (/src/app/fetch.go:12)", "github.com/Scalingo/app.main (/src/app/main.go:5)"},
+		},
+		"a wrapped error with a stack trace": {
+			err:           fmt.Errorf("deploy app: %w", stackTracedError{frames: frames}),
+			expectedStack: []string{"github.com/Scalingo/app.fetch (/src/app/fetch.go:12)", "github.com/Scalingo/app.main (/src/app/main.go:5)"},
+		},
+		"an error with an empty stack trace": {
+			err: stackTracedError{},
+		},
+		"an error without stack trace": {
+			err: errors.New("connection refused"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("ERRORS_STACK_TRACE", "true")
+			hook := TestLastEntryHook{}
+			log := Default(WithHooks([]logrus.Hook{&hook}))
+
+			log.WithError(test.err).Info("test")
+
+			require.NotNil(t, hook.lastEntry)
+			assert.Equal(t, test.expectedStack, hook.lastEntry.Data[ErrorStackField])
+		})
+	}
+}
+
+func TestWithErrorStack(t *testing.T) {
+	err := stackTracedError{frames: []runtime.Frame{{Function: "github.com/Scalingo/app.main", File: "/src/app/main.go", Line: 5}}}
+
+	t.Run("the stack trace is not added if the capture is disabled", func(t *testing.T) {
+		t.Setenv("ERRORS_STACK_TRACE", "false")
+		hook := TestLastEntryHook{}
+		log := Default(WithHooks([]logrus.Hook{&hook}))
+
+		log.WithError(err).Info("test")
+
+		require.NotNil(t, hook.lastEntry)
+		assert.NotContains(t, hook.lastEntry.Data, ErrorStackField)
+	})
+
+	t.Run("the option adds the stack trace once", func(t *testing.T) {
+		t.Setenv("ERRORS_STACK_TRACE", "true")
+		log := defaultLogrusLogger(t, WithErrorStack())
+
+		count := 0
+		for _, hook := range log.Hooks[logrus.InfoLevel] {
+			if _, ok := hook.(errorStackHook); ok {
+				count++
+			}
+		}
+		assert.Equal(t, 1, count)
+	})
+}
@@ -0,0 +1,195 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+	"weak"
+
+	"github.com/sirupsen/logrus"
+)
+
+var levels = &levelManager{}
+
+// levelManager changes at runtime the level of the loggers created by Default. The loggers are referenced weakly so
+// that the loggers which are not used anymore can be garbage collected.
+type levelManager struct {
+	lock    sync.Mutex
+	loggers []managedLogger
+	// pruneThreshold is the number of loggers above which the garbage collected loggers are removed
+	pruneThreshold int
+
+	override    *logrus.Level
+	revertAt    time.Time
+	revertTimer *time.Timer
+}
+
+type managedLogger struct {
+	logger weak.Pointer[logrus.Logger]
+	// level is the level configured when the logger was created
+	level logrus.Level
+}
+
+// SetLevel changes the level of all the loggers created by Default, including the ones created afterwards. If
+// revertAfter is positive, the configured levels are restored after this duration.
+func SetLevel(level logrus.Level, revertAfter time.Duration) {
+	levels.setLevel(level, revertAfter)
+}
+
+// ResetLevel restores the level configured when the loggers were created
+func ResetLevel() {
+	levels.reset()
+}
+
+func (m *levelManager) register(l *logrus.Logger) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if len(m.loggers) >= m.pruneThreshold {
+		m.prune()
+	}
+
+	m.loggers = append(m.loggers, managedLogger{logger: weak.Make(l), level: l.GetLevel()})
+	if m.override != nil {
+		l.SetLevel(*m.override)
+	}
+}
+
+// prune removes the loggers which have been garbage collected. It must be called with the lock held.
+func (m *levelManager) prune() {
+	loggers := m.loggers[:0]
+	for _, managed := range m.loggers {
+		if managed.logger.Value() != nil {
+			loggers = append(loggers, managed)
+		}
+	}
+	clear(m.loggers[len(loggers):])
+	m.loggers = loggers
+	m.pruneThreshold = max(2*len(loggers), 64)
+}
+
+func (m *levelManager) setLevel(level logrus.Level, revertAfter time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.stopRevertTimer()
+	m.override = &level
+	for _, managed := range m.loggers {
+		if l := managed.logger.Value(); l != nil {
+			l.SetLevel(level)
+		}
+	}
+
+	if revertAfter > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(revertAfter, func() {
+			m.lock.Lock()
+			defer m.lock.Unlock()
+			// The level may have been changed again while the timer was firing
+			if m.revertTimer == timer {
+				m.resetLocked()
+			}
+		})
+		m.revertAt = time.Now().Add(revertAfter)
+		m.revertTimer = timer
+	}
+}
+
+func (m *levelManager) reset() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.resetLocked()
+}
+
+// resetLocked must be called with the lock held
+func (m *levelManager) resetLocked() {
+	m.stopRevertTimer()
+	m.override = nil
+	for _, managed := range m.loggers {
+		if l := managed.logger.Value(); l != nil {
+			l.SetLevel(managed.level)
+		}
+	}
+}
+
+// stopRevertTimer must be called with the lock held
+func (m *levelManager) stopRevertTimer() {
+	if m.revertTimer != nil {
+		m.revertTimer.Stop()
+		m.revertTimer = nil
+	}
+	m.revertAt = time.Time{}
+}
+
+func (m *levelManager) status() levelStatus {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	status := levelStatus{Level: logLevel().String()}
+	if m.override != nil {
+		status.Level = m.override.String()
+		status.Overridden = true
+	}
+	if !m.revertAt.IsZero() {
+		revertAt := m.revertAt
+		status.RevertAt = &revertAt
+	}
+	return status
+}
+
+type levelStatus struct {
+	Level      string     `json:"level"`
+	Overridden bool       `json:"overridden"`
+	RevertAt   *time.Time `json:"revert_at,omitempty"`
+}
+
+type levelRequest struct {
+	Level    string `json:"level"`
+	Duration string `json:"duration"`
+}
+
+// LevelHandler returns the handler changing the level of the loggers created by Default. It is meant to be mounted on
+// an admin server:
+//   - GET returns the current level
+//   - PUT with a body like {"level": "debug", "duration": "10m"} changes the level, and restores it after the optional
+//     duration
+//   - DELETE restores the configured level
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var request levelRequest
+			err := json.NewDecoder(r.Body).Decode(&request)
+			if err != nil {
+				http.Error(w, "invalid JSON body", http.StatusBadRequest)
+				return
+			}
+			level, err := logrus.ParseLevel(request.Level)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			var revertAfter time.Duration
+			if request.Duration != "" {
+				revertAfter, err = time.ParseDuration(request.Duration)
+				if err != nil {
+					http.Error(w, "invalid duration: "+err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+			SetLevel(level, revertAfter)
+		case http.MethodDelete:
+			ResetLevel()
+		default:
+			w.Header().Set("Allow", "GET, PUT, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(levels.status())
+	})
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package logger
+
+import (
+	"context"
+	"time"
+)
+
+// HandleLevelSignals does nothing on platforms without the SIGUSR1 and SIGUSR2 signals
+func HandleLevelSignals(_ context.Context, _ time.Duration) {}
@@ -0,0 +1,41 @@
+//go:build unix
+
+package logger
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HandleLevelSignals changes the level of the loggers created by Default when the process receives a signal, until
+// the context is canceled:
+//   - SIGUSR1 sets the debug level, restored after revertAfter if positive
+//   - SIGUSR2 restores the configured level
+func HandleLevelSignals(ctx context.Context, revertAfter time.Duration) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-signals:
+				switch sig {
+				case syscall.SIGUSR1:
+					SetLevel(logrus.DebugLevel, revertAfter)
+					Get(ctx).WithField("revert_after", revertAfter.String()).Info("Log level set to debug")
+				case syscall.SIGUSR2:
+					ResetLevel()
+					Get(ctx).Info("Log level restored")
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,32 @@
+//go:build unix
+
+package logger
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleLevelSignals(t *testing.T) {
+	t.Cleanup(ResetLevel)
+	t.Setenv("LOGGER_LEVEL", "info")
+	log := defaultLogrusLogger(t)
+	ctx := ToCtx(t.Context(), log)
+
+	HandleLevelSignals(ctx, 0)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool {
+		return log.GetLevel() == logrus.DebugLevel
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	assert.Eventually(t, func() bool {
+		return log.GetLevel() == logrus.InfoLevel
+	}, time.Second, 5*time.Millisecond)
+}
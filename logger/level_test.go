@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func defaultLogrusLogger(t *testing.T, opts ...Opt) *logrus.Logger {
+	t.Helper()
+
+	log, ok := Default(opts...).(*logrus.Logger)
+	require.True(t, ok)
+	return log
+}
+
+func TestSetLevel(t *testing.T) {
+	t.Cleanup(ResetLevel)
+	t.Setenv("LOGGER_LEVEL", "info")
+
+	t.Run("it changes the level of the existing and new loggers until reset", func(t *testing.T) {
+		t.Cleanup(ResetLevel)
+		existing := defaultLogrusLogger(t)
+		warnLogger := defaultLogrusLogger(t, WithLogLevel(logrus.WarnLevel))
+
+		SetLevel(logrus.DebugLevel, 0)
+		created := defaultLogrusLogger(t)
+
+		assert.Equal(t, logrus.DebugLevel, existing.GetLevel())
+		assert.Equal(t, logrus.DebugLevel, warnLogger.GetLevel())
+		assert.Equal(t, logrus.DebugLevel, created.GetLevel())
+
+		ResetLevel()
+
+		assert.Equal(t, logrus.InfoLevel, existing.GetLevel())
+		assert.Equal(t, logrus.WarnLevel, warnLogger.GetLevel())
+		assert.Equal(t, logrus.InfoLevel, created.GetLevel())
+	})
+
+	t.Run("it restores the level after the duration", func(t *testing.T) {
+		t.Cleanup(ResetLevel)
+		log := defaultLogrusLogger(t)
+
+		SetLevel(logrus.TraceLevel, 10*time.Millisecond)
+		assert.Equal(t, logrus.TraceLevel, log.GetLevel())
+
+		assert.Eventually(t, func() bool {
+			return log.GetLevel() == logrus.InfoLevel
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("a new level cancels the previous revert", func(t *testing.T) {
+		t.Cleanup(ResetLevel)
+		log := defaultLogrusLogger(t)
+
+		SetLevel(logrus.TraceLevel, 10*time.Millisecond)
+		SetLevel(logrus.DebugLevel, 0)
+		time.Sleep(30 * time.Millisecond)
+
+		assert.Equal(t, logrus.DebugLevel, log.GetLevel())
+	})
+
+	t.Run("it changes and restores the component levels", func(t *testing.T) {
+		t.Cleanup(ResetLevel)
+		t.Setenv("LOGGER_LEVELS", "cron=debug")
+		var output strings.Builder
+		log := defaultLogrusLogger(t, WithOutput(&output))
+		cronLog := WithComponent(log, "cron")
+
+		SetLevel(logrus.WarnLevel, 0)
+		log.Info("default info")
+		cronLog.Debug("cron debug")
+		WithComponent(log, "cron").Debug("new cron debug")
+
+		assert.NotContains(t, output.String(), "default info")
+		assert.NotContains(t, output.String(), "cron debug")
+
+		ResetLevel()
+		log.Info("restored info")
+		cronLog.Debug("restored cron debug")
+
+		assert.Contains(t, output.String(), "restored info")
+		assert.Contains(t, output.String(), "restored cron debug")
+	})
+}
+
+func TestLevelHandler(t *testing.T) {
+	t.Cleanup(ResetLevel)
+	t.Setenv("LOGGER_LEVEL", "info")
+	log := defaultLogrusLogger(t)
+
+	serve := func(method, body string) (*httptest.ResponseRecorder, levelStatus) {
+		recorder := httptest.NewRecorder()
+		LevelHandler().ServeHTTP(recorder, httptest.NewRequest(method, "/logger/level", strings.NewReader(body)))
+
+		var status levelStatus
+		if recorder.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+		}
+		return recorder, status
+	}
+
+	recorder, status := serve(http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, levelStatus{Level: "info"}, status)
+
+	recorder, status = serve(http.MethodPut, `{"level": "debug", "duration": "1h"}`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "debug", status.Level)
+	assert.True(t, status.Overridden)
+	require.NotNil(t, status.RevertAt)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *status.RevertAt, time.Minute)
+	assert.Equal(t, logrus.DebugLevel, log.GetLevel())
+
+	recorder, _ = serve(http.MethodPut, `{"level": "verbose"}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder, _ = serve(http.MethodPut, `{"level": "debug", "duration": "soon"}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder, status = serve(http.MethodDelete, "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, levelStatus{Level: "info"}, status)
+	assert.Equal(t, logrus.InfoLevel, log.GetLevel())
+
+	recorder, _ = serve(http.MethodPost, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
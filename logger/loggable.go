@@ -2,8 +2,14 @@ package logger
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"maps"
+	"os"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -37,6 +43,16 @@ type Loggable interface {
 //
 // If both "omitempty" and "omitzero" are specified, the field will be omitted if the value is either empty or zero (or both).
 //
+// The sensitive fields can be hidden with the following options:
+//   - "redact" replaces the value with "[REDACTED]"
+//   - "hash" replaces the value with a short hash, to correlate the logs without leaking the value. The hash is a
+//     HMAC-SHA256 keyed with the LOGGER_FIELDS_HASH_KEY environment variable. The value is redacted if it is not set.
+//   - "mask=N" replaces all the characters of the value but the last N ones with '*'. The value is redacted if N is
+//     not a valid number.
+//
+// The nested structs with "log" tags (or implementing Loggable) and the slices of such structs are flattened, with
+// keys like "prefix_field.nested" and "prefix_field.0.nested".
+//
 // Parameters:
 // - value: The struct to extract fields from.
 // - prefix: The prefix to add to each field key.
@@ -56,40 +72,7 @@ func FieldsFor(prefix string, value interface{}) logrus.Fields {
 	val := reflect.Indirect(reflect.ValueOf(value))
 
 	if val.Kind() == reflect.Struct {
-		for i := 0; i < val.NumField(); i++ {
-			structField := val.Type().Field(i)
-			tag, found := structField.Tag.Lookup("log")
-			if !found {
-				// if the `log` tag has not been found, iterate to the next structure field
-				continue
-			}
-
-			fieldValue := val.Field(i)
-			tagName, tagOpts := parseTag(tag)
-			if tagOpts.contains("omitempty") && isEmptyValue(fieldValue) {
-				// do not keep the log field if `omitempty` is set and it is an empty value
-				continue
-			}
-
-			if tagOpts.contains("omitzero") {
-				fieldIsZero := determineIsZeroMethod(structField.Type)
-
-				if (fieldIsZero == nil && fieldValue.IsZero()) ||
-					(fieldIsZero != nil && fieldIsZero(fieldValue)) {
-					// do not keep the log field if `omitzero` is set and it is a zero value
-					continue
-				}
-
-				// the field do NOT have a zero value, add it to the logger
-			}
-
-			fieldValueObjectId, ok := reflect.TypeAssert[bson.ObjectId](fieldValue)
-			if ok {
-				fields[fmt.Sprintf("%s_%s", prefix, tagName)] = fieldValueObjectId.Hex()
-				continue
-			}
-			fields[fmt.Sprintf("%s_%s", prefix, tagName)] = fieldValue.Interface()
-		}
+		addStructFields(fields, prefix+"_", val, 0)
 	}
 
 	if len(fields) != 0 {
@@ -109,6 +92,154 @@ func WithStructToCtx(ctx context.Context, prefix string, value interface{}) (con
 	return WithFieldsToCtx(ctx, FieldsFor(prefix, value))
 }
 
+// maxFieldsDepth is the maximum depth of the nested structs and slices flattened by FieldsFor
+const maxFieldsDepth = 5
+
+// addStructFields adds the struct fields having a `log` tag, with a key made of the key prefix and the tag name. It
+// returns whether the struct has some `log` tags.
+func addStructFields(fields logrus.Fields, keyPrefix string, val reflect.Value, depth int) bool {
+	found := false
+	for i := 0; i < val.NumField(); i++ {
+		structField := val.Type().Field(i)
+		tag, ok := structField.Tag.Lookup("log")
+		if !ok {
+			// if the `log` tag has not been found, iterate to the next structure field
+			continue
+		}
+		found = true
+
+		fieldValue := val.Field(i)
+		tagName, tagOpts := parseTag(tag)
+		if tagOpts.contains("omitempty") && isEmptyValue(fieldValue) {
+			// do not keep the log field if `omitempty` is set and it is an empty value
+			continue
+		}
+
+		if tagOpts.contains("omitzero") {
+			fieldIsZero := determineIsZeroMethod(structField.Type)
+
+			if (fieldIsZero == nil && fieldValue.IsZero()) ||
+				(fieldIsZero != nil && fieldIsZero(fieldValue)) {
+				// do not keep the log field if `omitzero` is set and it is a zero value
+				continue
+			}
+
+			// the field do NOT have a zero value, add it to the logger
+		}
+
+		addField(fields, keyPrefix+tagName, fieldValue, tagOpts, depth)
+	}
+	return found
+}
+
+// addField adds a struct field value, redacted according to the tag options. The nested structs with `log` tags (or
+// implementing Loggable) and the slices of such structs are flattened with dotted keys.
+func addField(fields logrus.Fields, key string, fieldValue reflect.Value, tagOpts tagOptions, depth int) {
+	if tagOpts.contains("redact") {
+		fields[key] = defaultRedactionReplacement
+		return
+	}
+	if tagOpts.contains("hash") {
+		fields[key] = hashFieldValue(fieldValue)
+		return
+	}
+	if maskOption, ok := tagOpts.value("mask"); ok || tagOpts.contains("mask") {
+		// An invalid mask option fails closed: the value is fully redacted
+		visible, err := strconv.Atoi(maskOption)
+		if err != nil || visible < 0 {
+			fields[key] = defaultRedactionReplacement
+			return
+		}
+		fields[key] = maskFieldValue(fieldValue, visible)
+		return
+	}
+
+	fieldValueObjectId, ok := reflect.TypeAssert[bson.ObjectId](fieldValue)
+	if ok {
+		fields[key] = fieldValueObjectId.Hex()
+		return
+	}
+
+	if depth < maxFieldsDepth && addNestedFields(fields, key, fieldValue, depth+1) {
+		return
+	}
+	fields[key] = fieldValue.Interface()
+}
+
+// addNestedFields flattens a nested struct or slice of structs. It returns false if the value cannot be flattened.
+func addNestedFields(fields logrus.Fields, key string, val reflect.Value, depth int) bool {
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return false
+		}
+		val = val.Elem()
+	}
+
+	if loggableValue, ok := reflect.TypeAssert[Loggable](val); ok {
+		for k, v := range loggableValue.LogFields() {
+			fields[key+"."+k] = v
+		}
+		return true
+	}
+
+	switch val.Kind() {
+	case reflect.Struct:
+		return addStructFields(fields, key+".", val, depth)
+	case reflect.Slice, reflect.Array:
+		if val.Len() == 0 {
+			return false
+		}
+		// The slice is only flattened if all its elements can be flattened
+		elements := logrus.Fields{}
+		for i := range val.Len() {
+			if !addNestedFields(elements, key+"."+strconv.Itoa(i), val.Index(i), depth) {
+				return false
+			}
+		}
+		maps.Copy(fields, elements)
+		return true
+	default:
+		return false
+	}
+}
+
+// hashFieldValue returns a short HMAC-SHA256 of the value keyed with the LOGGER_FIELDS_HASH_KEY environment variable,
+// so that the logs can be correlated without leaking the value. Without key, the value is redacted: a plain hash of a
+// low entropy value, like an email, can be reversed.
+func hashFieldValue(val reflect.Value) string {
+	key := os.Getenv("LOGGER_FIELDS_HASH_KEY")
+	if key == "" {
+		return defaultRedactionReplacement
+	}
+	hasher := hmac.New(sha256.New, []byte(key))
+	hasher.Write([]byte(fieldValueString(val)))
+	return hex.EncodeToString(hasher.Sum(nil))[:16]
+}
+
+// maskFieldValue replaces all the characters of the value but the last visible ones with '*'. The values which are
+// not longer than the visible characters are fully masked.
+func maskFieldValue(val reflect.Value, visible int) string {
+	runes := []rune(fieldValueString(val))
+	if len(runes) <= visible {
+		return strings.Repeat("*", len(runes))
+	}
+	masked := len(runes) - max(visible, 0)
+	return strings.Repeat("*", masked) + string(runes[masked:])
+}
+
+func fieldValueString(val reflect.Value) string {
+	if objectID, ok := reflect.TypeAssert[bson.ObjectId](val); ok {
+		return objectID.Hex()
+	}
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return ""
+		}
+		val = val.Elem()
+	}
+	return fmt.Sprint(val.Interface())
+}
+
 // tagOptions is the string following a comma in a struct field's "log"
 // tag, or the empty string. It does not include the leading comma.
 type tagOptions string
@@ -138,6 +269,20 @@ func (o tagOptions) contains(optionName string) bool {
 	return false
 }
 
+// value returns the value of an option like "mask=4", and whether the option is present
+func (o tagOptions) value(optionName string) (string, bool) {
+	s := string(o)
+	for s != "" {
+		var option string
+		option, s, _ = strings.Cut(s, ",")
+		name, value, found := strings.Cut(option, "=")
+		if found && name == optionName {
+			return value, true
+		}
+	}
+	return "", false
+}
+
 func isEmptyValue(v reflect.Value) bool {
 	switch v.Kind() {
 	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
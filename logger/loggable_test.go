@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/sirupsen/logrus"
@@ -253,7 +254,7 @@ func TestFieldsFor(t *testing.T) {
 		}, FieldsFor("prefix", true))
 	})
 
-	// We didn't implement yet the support for struct in struct.
+	// A struct in struct without `log` tags cannot be flattened.
 	// Hence the output is uggly.
 	t.Run("when a struct in struct has a tag", func(t *testing.T) {
 		// Given a struct with tags
@@ -308,3 +309,112 @@ func TestFieldsFor(t *testing.T) {
 		}, fields)
 	})
 }
+
+type structWithSensitiveTags struct {
+	Email    string        `log:"email,redact"`
+	Token    string        `log:"token,hash"`
+	Card     string        `log:"card,mask=4"`
+	Short    string        `log:"short,mask=4"`
+	ObjectID bson.ObjectId `log:"object_id,hash"`
+}
+
+type structWithNestedTags struct {
+	Name       string                     `log:"name"`
+	Owner      structWithSensitiveTags    `log:"owner"`
+	Collabs    []structWithSensitiveTags  `log:"collaborators"`
+	Pointer    *structWithTagsAndLoggable `log:"pointer"`
+	NilPointer *structWithSensitiveTags   `log:"nil_pointer"`
+	Tags       []string                   `log:"tags"`
+}
+
+func TestFieldsFor_SensitiveFields(t *testing.T) {
+	objectID := bson.ObjectIdHex("5f1d7f3e9b1e8a0001a2b3c4")
+	s := structWithSensitiveTags{
+		Email:    "john@example.com",
+		Token:    "tk-us-secret",
+		Card:     "4242424242424242",
+		Short:    "abc",
+		ObjectID: objectID,
+	}
+
+	t.Run("it redacts, hashes and masks the fields", func(t *testing.T) {
+		t.Setenv("LOGGER_FIELDS_HASH_KEY", "my-key")
+
+		fields := FieldsFor("user", s)
+
+		assert.Equal(t, logrus.Fields{
+			"user_email":     "[REDACTED]",
+			"user_token":     hashFieldValue(reflect.ValueOf("tk-us-secret")),
+			"user_card":      "************4242",
+			"user_short":     "***",
+			"user_object_id": hashFieldValue(reflect.ValueOf(objectID.Hex())),
+		}, fields)
+		assert.Len(t, fields["user_token"], 16)
+		// The same value always has the same hash
+		assert.Equal(t, fields["user_token"], FieldsFor("user", s)["user_token"])
+	})
+
+	t.Run("it uses a HMAC with the configured key", func(t *testing.T) {
+		t.Setenv("LOGGER_FIELDS_HASH_KEY", "my-key")
+		withKey := FieldsFor("user", s)["user_token"]
+		t.Setenv("LOGGER_FIELDS_HASH_KEY", "another-key")
+		withAnotherKey := FieldsFor("user", s)["user_token"]
+
+		assert.NotEqual(t, withKey, withAnotherKey)
+	})
+
+	t.Run("it redacts the hashed fields if no key is configured", func(t *testing.T) {
+		t.Setenv("LOGGER_FIELDS_HASH_KEY", "")
+
+		fields := FieldsFor("user", s)
+
+		assert.Equal(t, "[REDACTED]", fields["user_token"])
+		assert.Equal(t, "[REDACTED]", fields["user_object_id"])
+	})
+
+	t.Run("it redacts the masked fields if the mask option is invalid", func(t *testing.T) {
+		invalid := struct {
+			Bare     string `log:"bare,mask"`
+			Invalid  string `log:"invalid,mask=x"`
+			Negative string `log:"negative,mask=-1"`
+		}{Bare: "secret", Invalid: "secret", Negative: "secret"}
+
+		assert.Equal(t, logrus.Fields{
+			"user_bare":     "[REDACTED]",
+			"user_invalid":  "[REDACTED]",
+			"user_negative": "[REDACTED]",
+		}, FieldsFor("user", invalid))
+	})
+}
+
+func TestFieldsFor_NestedStructs(t *testing.T) {
+	t.Setenv("LOGGER_FIELDS_HASH_KEY", "my-key")
+	owner := structWithSensitiveTags{Email: "john@example.com", Card: "1234"}
+	s := structWithNestedTags{
+		Name:    "my-app",
+		Owner:   owner,
+		Collabs: []structWithSensitiveTags{{Email: "jane@example.com", Card: "5678"}},
+		Pointer: &structWithTagsAndLoggable{},
+		Tags:    []string{"a", "b"},
+	}
+
+	fields := FieldsFor("app", s)
+
+	emptyHash := hashFieldValue(reflect.ValueOf(""))
+	assert.Equal(t, logrus.Fields{
+		"app_name":                      "my-app",
+		"app_owner.email":               "[REDACTED]",
+		"app_owner.token":               emptyHash,
+		"app_owner.card":                "****",
+		"app_owner.short":               "",
+		"app_owner.object_id":           emptyHash,
+		"app_collaborators.0.email":     "[REDACTED]",
+		"app_collaborators.0.token":     emptyHash,
+		"app_collaborators.0.card":      "****",
+		"app_collaborators.0.short":     "",
+		"app_collaborators.0.object_id": emptyHash,
+		"app_pointer.another":           "test",
+		"app_nil_pointer":               (*structWithSensitiveTags)(nil),
+		"app_tags":                      []string{"a", "b"},
+	}, fields)
+}
@@ -34,6 +34,13 @@ func WithHooks(hooks []logrus.Hook) Opt {
 	}
 }
 
+// WithReportCaller adds the calling method as a field of the entries
+func WithReportCaller(enabled bool) Opt {
+	return func(l *logrus.Logger) {
+		l.SetReportCaller(enabled)
+	}
+}
+
 func WithOutput(w io.Writer) Opt {
 	return func(l *logrus.Logger) {
 		l.SetOutput(w)
@@ -44,19 +51,38 @@ func WithOutput(w io.Writer) Opt {
 // If the regular expression is nil, the field is replaced by "REDACTED".
 func WithSetRedactedFields(fields []*RedactionOption) Opt {
 	return func(l *logrus.Logger) {
-		l.SetFormatter(&RedactingFormatter{
-			Formatter: l.Formatter,
-			fields:    fields,
-		})
+		redactingFormatter(l).fields = fields
 	}
 }
 
+// WithSecretDetectors redacts the secrets found in the message and in the string values of the entries, including
+// the nested ones and the error messages. DefaultSecretDetectors contains the built-in detectors.
+func WithSecretDetectors(detectors ...*SecretDetector) Opt {
+	return func(l *logrus.Logger) {
+		redactingFormatter(l).detectors = detectors
+	}
+}
+
+// redactingFormatter returns the RedactingFormatter of the logger, wrapping the current formatter if needed
+func redactingFormatter(l *logrus.Logger) *RedactingFormatter {
+	if f, ok := l.Formatter.(*RedactingFormatter); ok {
+		return f
+	}
+	f := &RedactingFormatter{Formatter: l.Formatter}
+	l.SetFormatter(f)
+	return f
+}
+
 // Default generate a logrus logger with the configuration defined in the environment and the hooks used in the plugins
 func Default(opts ...Opt) logrus.FieldLogger {
 	logger := logrus.New()
 	logger.SetLevel(logLevel())
+	logger.SetReportCaller(reportCaller())
 	logger.Formatter = formatter()
 
+	if errorStack() {
+		logger.Hooks.Add(errorStackHook{})
+	}
 	for _, hook := range Plugins().Hooks() {
 		logger.Hooks.Add(hook)
 	}
@@ -65,6 +91,11 @@ func Default(opts ...Opt) logrus.FieldLogger {
 		opt(logger)
 	}
 
+	// Applied last so that the hooks, level and formatter set by the options are the ones of the components
+	withSamplingHooks(logger)
+	withComponentLevels(logger, componentLevels())
+	levels.register(logger)
+
 	var fieldLogger logrus.FieldLogger = logger
 	if os.Getenv("REGION_NAME") != "" {
 		fieldLogger = fieldLogger.WithField("region", os.Getenv("REGION_NAME"))
@@ -83,13 +114,14 @@ func AddLoggerToContext(ctx context.Context) context.Context {
 	return context.WithValue(ctx, loggerContextKey, Default())
 }
 
-// Get return the logger stored in the context or create a new one if the logger is not set
+// Get return the logger stored in the context or create a new one if the logger is not set. If the context carries an
+// active OpenTelemetry span, the context is attached to the logger entries (see WithTraceFields).
 func Get(ctx context.Context) logrus.FieldLogger {
 	if logger, ok := ctx.Value(loggerContextKey).(logrus.FieldLogger); ok {
-		return logger
+		return withSpanContext(ctx, logger)
 	}
 
-	return Default().WithField("invalid_context", true)
+	return withSpanContext(ctx, Default().WithField("invalid_context", true))
 }
 
 // WithFieldToCtx adds the field to the logger and adds the logger to the context
@@ -1,7 +1,9 @@
 package rollbarplugin
 
 import (
+	"errors"
 	"os"
+	"runtime"
 
 	"github.com/rollbar/rollbar-go"
 	"github.com/sirupsen/logrus"
@@ -43,6 +45,19 @@ func (p RollbarPlugin) Hook() (bool, logrus.Hook) {
 		environment = "undefined"
 	}
 	rollbar.SetEnvironment(environment)
+	rollbar.SetStackTracer(stackTracer)
 
 	return true, logrus_rollbar.New()
 }
+
+// stackTracer reports the stack trace captured by the errors of github.com/Scalingo/go-utils/errors, pointing where
+// the error has been created rather than where it has been logged
+func stackTracer(err error) ([]runtime.Frame, bool) {
+	var tracer interface{ StackTrace() []runtime.Frame }
+	if errors.As(err, &tracer) {
+		if frames := tracer.StackTrace(); len(frames) > 0 {
+			return frames, true
+		}
+	}
+	return rollbar.DefaultStackTracer(err)
+}
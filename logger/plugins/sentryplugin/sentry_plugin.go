@@ -0,0 +1,221 @@
+package sentryplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Scalingo/go-utils/logger"
+)
+
+const (
+	flushTimeout   = 5 * time.Second
+	maxBreadcrumbs = 50
+	// maxErrorDepth is the maximum number of wrapped errors reported in the exception of the events
+	maxErrorDepth = 10
+)
+
+var (
+	initOnce sync.Once
+	initErr  error
+	// hook is the hook of all the loggers, created once with the current Sentry hub
+	hook *Hook
+)
+
+type SentryPlugin struct{}
+
+// Register the plugin to the logger library
+func Register() {
+	logger.Plugins().RegisterPlugin(SentryPlugin{})
+}
+
+// Close blocks until the events are sent to Sentry or the flush timeout is reached.
+func Close() {
+	sentry.Flush(flushTimeout)
+}
+
+func (p SentryPlugin) Name() string {
+	return "sentry"
+}
+
+// Generate the hook
+func (p SentryPlugin) Hook() (bool, logrus.Hook) {
+	dsn := os.Getenv("SENTRY_DSN")
+	if dsn == "" {
+		return false, nil
+	}
+
+	initOnce.Do(func() {
+		environment := os.Getenv("SENTRY_ENVIRONMENT")
+		if environment == "" {
+			environment = os.Getenv("GO_ENV")
+		}
+		if environment == "" {
+			environment = "undefined"
+		}
+		initErr = sentry.Init(sentry.ClientOptions{
+			Dsn:            dsn,
+			Environment:    environment,
+			Release:        os.Getenv("SENTRY_RELEASE"),
+			MaxBreadcrumbs: maxBreadcrumbs,
+		})
+		if initErr != nil {
+			return
+		}
+
+		var opts []HookOpt
+		if breadcrumbsLevel, err := logrus.ParseLevel(os.Getenv("SENTRY_BREADCRUMBS_LEVEL")); err == nil {
+			opts = append(opts, WithBreadcrumbsLevel(breadcrumbsLevel))
+		}
+		hook = NewHook(sentry.CurrentHub(), opts...)
+	})
+	if initErr != nil {
+		fmt.Fprintf(os.Stderr, "fail to initialize the Sentry client: %v\n", initErr)
+		return false, nil
+	}
+
+	return true, hook
+}
+
+// ToCtx adds a clone of the Sentry hub to the context, so that the breadcrumbs recorded by the entries having this
+// context (e.g. with logger.Get) are only attached to the events of the same context, like an HTTP request or a job.
+func ToCtx(ctx context.Context) context.Context {
+	hub := sentry.GetHubFromContext(ctx)
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+	return sentry.SetHubOnContext(ctx, hub.Clone())
+}
+
+// Hook sends the entries of level error and above to Sentry as events. The entries of lower levels can be recorded as
+// breadcrumbs attached to the next events (see WithBreadcrumbsLevel). The hub of the entry context is used if there is
+// one (see ToCtx), otherwise the hub of the hook.
+type Hook struct {
+	hub              *sentry.Hub
+	breadcrumbsLevel logrus.Level
+}
+
+// HookOpt is a function-option type for NewHook
+type HookOpt func(*Hook)
+
+// WithBreadcrumbsLevel records the entries from the warning level down to the given level as breadcrumbs. By default,
+// no breadcrumb is recorded.
+func WithBreadcrumbsLevel(level logrus.Level) HookOpt {
+	return func(h *Hook) {
+		h.breadcrumbsLevel = level
+	}
+}
+
+func NewHook(hub *sentry.Hub, opts ...HookOpt) *Hook {
+	h := &Hook{hub: hub, breadcrumbsLevel: logrus.ErrorLevel}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func (h *Hook) hubFor(entry *logrus.Entry) *sentry.Hub {
+	if entry.Context != nil {
+		if hub := sentry.GetHubFromContext(entry.Context); hub != nil {
+			return hub
+		}
+	}
+	return h.hub
+}
+
+// Levels are the error level and above, and the levels of the breadcrumbs
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels[:max(h.breadcrumbsLevel, logrus.ErrorLevel)+1]
+}
+
+// Fire sends the entry redacted like the output of its logger (see logger.WithSetRedactedFields and
+// logger.WithSecretDetectors)
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	hub := h.hubFor(entry)
+	redactingFormatter := logger.RedactingFormatterOf(entry.Logger)
+	// The error of the original entry is kept for the exception, the redaction may have replaced it with a string
+	err, _ := entry.Data[logrus.ErrorKey].(error)
+	entry = redactingFormatter.Redact(entry)
+
+	if entry.Level > logrus.ErrorLevel {
+		hub.AddBreadcrumb(&sentry.Breadcrumb{
+			Type:      "default",
+			Category:  "log",
+			Message:   entry.Message,
+			Data:      fields(entry),
+			Level:     level(entry.Level),
+			Timestamp: entry.Time,
+		}, nil)
+		return nil
+	}
+
+	event := sentry.NewEvent()
+	event.Level = level(entry.Level)
+	event.Message = entry.Message
+	event.Timestamp = entry.Time
+	event.Logger = "logrus"
+
+	if data := fields(entry); len(data) > 0 {
+		event.Contexts["fields"] = data
+	}
+	if component, ok := entry.Data[logger.ComponentField].(string); ok {
+		event.Tags[logger.ComponentField] = component
+	}
+
+	if err != nil {
+		event.SetException(err, maxErrorDepth)
+		for i := range event.Exception {
+			event.Exception[i].Value = redactingFormatter.RedactSecrets(event.Exception[i].Value)
+		}
+		event.Fingerprint = Fingerprint(err, entry.Message)
+	}
+
+	hub.CaptureEvent(event)
+	return nil
+}
+
+// Fingerprint groups the Sentry events by the type of the root cause of the error and the message of the entry, whatever
+// the context added by the wrapping errors. The message of the root cause is not used as it often contains dynamic
+// values, like IDs or addresses.
+func Fingerprint(err error, message string) []string {
+	rootCause := err
+	for unwrappedErr := err; unwrappedErr != nil; unwrappedErr = errors.Unwrap(unwrappedErr) {
+		rootCause = unwrappedErr
+	}
+	return []string{fmt.Sprintf("%T", rootCause), message}
+}
+
+func fields(entry *logrus.Entry) map[string]any {
+	data := make(map[string]any, len(entry.Data))
+	for key, value := range entry.Data {
+		if key == logrus.ErrorKey {
+			continue
+		}
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		data[key] = value
+	}
+	return data
+}
+
+func level(level logrus.Level) sentry.Level {
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return sentry.LevelFatal
+	case logrus.ErrorLevel:
+		return sentry.LevelError
+	case logrus.WarnLevel:
+		return sentry.LevelWarning
+	case logrus.InfoLevel:
+		return sentry.LevelInfo
+	default:
+		return sentry.LevelDebug
+	}
+}
@@ -0,0 +1,266 @@
+package webhookplugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Scalingo/go-utils/logger"
+)
+
+const (
+	defaultBatchSize            = 10
+	defaultFlushInterval        = 5 * time.Second
+	defaultMaxRequestsPerMinute = 30
+	defaultQueueSize            = 1000
+	defaultTimeout              = 10 * time.Second
+)
+
+var (
+	hookOnce sync.Once
+	hook     *Hook
+)
+
+type WebhookPlugin struct{}
+
+// Register the plugin to the logger library
+func Register() {
+	logger.Plugins().RegisterPlugin(WebhookPlugin{})
+}
+
+// Close sends the pending entries and stops the hook of the plugin
+func Close() {
+	if hook != nil {
+		hook.Close()
+	}
+}
+
+func (p WebhookPlugin) Name() string {
+	return "webhook"
+}
+
+// Generate the hook. All the loggers share the same hook.
+func (p WebhookPlugin) Hook() (bool, logrus.Hook) {
+	config := ConfigFromEnv()
+	if config.URL == "" {
+		return false, nil
+	}
+
+	hookOnce.Do(func() {
+		hook = NewHook(config)
+	})
+	return true, hook
+}
+
+// Config configures the webhook hook. The zero values are replaced by the defaults.
+type Config struct {
+	// URL of a webhook accepting a JSON payload with a "text" field, like the Slack incoming webhooks
+	URL string
+	// Levels sent to the webhook (default: error, fatal and panic)
+	Levels []logrus.Level
+	// BatchSize is the maximum number of entries per request (default: 10)
+	BatchSize int
+	// FlushInterval is the maximum duration an entry waits before being sent (default: 5s)
+	FlushInterval time.Duration
+	// MaxRequestsPerMinute limits the requests sent to the webhook. The entries exceeding BatchSize while waiting are
+	// dropped (default: 30).
+	MaxRequestsPerMinute int
+	// QueueSize is the number of entries waiting to be batched. The entries are dropped when the queue is full
+	// (default: 1000).
+	QueueSize  int
+	HTTPClient *http.Client
+}
+
+// ConfigFromEnv reads the configuration from the LOGGER_WEBHOOK_URL, LOGGER_WEBHOOK_LEVEL (minimum level),
+// LOGGER_WEBHOOK_BATCH_SIZE, LOGGER_WEBHOOK_FLUSH_INTERVAL and LOGGER_WEBHOOK_MAX_REQUESTS_PER_MINUTE environment
+// variables.
+func ConfigFromEnv() Config {
+	config := Config{
+		URL: os.Getenv("LOGGER_WEBHOOK_URL"),
+	}
+	if level, err := logrus.ParseLevel(os.Getenv("LOGGER_WEBHOOK_LEVEL")); err == nil {
+		config.Levels = slices.Clone(logrus.AllLevels[:level+1])
+	}
+	if batchSize, err := strconv.Atoi(os.Getenv("LOGGER_WEBHOOK_BATCH_SIZE")); err == nil {
+		config.BatchSize = batchSize
+	}
+	if flushInterval, err := time.ParseDuration(os.Getenv("LOGGER_WEBHOOK_FLUSH_INTERVAL")); err == nil {
+		config.FlushInterval = flushInterval
+	}
+	if maxRequests, err := strconv.Atoi(os.Getenv("LOGGER_WEBHOOK_MAX_REQUESTS_PER_MINUTE")); err == nil {
+		config.MaxRequestsPerMinute = maxRequests
+	}
+	return config
+}
+
+// Hook sends the entries to a webhook asynchronously. The entries are batched and the requests are rate limited so
+// that logging never blocks nor floods the webhook.
+type Hook struct {
+	config  Config
+	entries chan string
+	done    chan struct{}
+
+	lock    sync.Mutex
+	dropped int
+	closed  bool
+}
+
+// NewHook starts the goroutine sending the entries. Close must be called to send the pending entries.
+func NewHook(config Config) *Hook {
+	if len(config.Levels) == 0 {
+		config.Levels = []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultFlushInterval
+	}
+	if config.MaxRequestsPerMinute <= 0 {
+		config.MaxRequestsPerMinute = defaultMaxRequestsPerMinute
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultQueueSize
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: defaultTimeout}
+	}
+
+	h := &Hook{
+		config:  config,
+		entries: make(chan string, config.QueueSize),
+		done:    make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+func (h *Hook) Levels() []logrus.Level {
+	return h.config.Levels
+}
+
+// Fire queues the entry, redacted like the output of its logger (see logger.WithSetRedactedFields and
+// logger.WithSecretDetectors)
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	text := format(logger.RedactingFormatterOf(entry.Logger).Redact(entry))
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.closed {
+		h.dropped++
+		return nil
+	}
+	select {
+	case h.entries <- text:
+	default:
+		h.dropped++
+	}
+	return nil
+}
+
+// Close sends the pending entries, ignoring the rate limit, and stops the hook. The entries fired afterwards are
+// dropped.
+func (h *Hook) Close() {
+	h.lock.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.entries)
+	}
+	h.lock.Unlock()
+	<-h.done
+}
+
+func (h *Hook) run() {
+	defer close(h.done)
+
+	ticker := time.NewTicker(h.config.FlushInterval)
+	defer ticker.Stop()
+
+	var batch []string
+	var nextRequest time.Time
+	flush := func(force bool) {
+		if len(batch) == 0 {
+			return
+		}
+		if !force && time.Now().Before(nextRequest) {
+			if len(batch) > h.config.BatchSize {
+				h.drop(len(batch) - h.config.BatchSize)
+				batch = batch[:h.config.BatchSize]
+			}
+			return
+		}
+		h.send(batch)
+		batch = nil
+		nextRequest = time.Now().Add(time.Minute / time.Duration(h.config.MaxRequestsPerMinute))
+	}
+
+	for {
+		select {
+		case entry, ok := <-h.entries:
+			if !ok {
+				flush(true)
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= h.config.BatchSize {
+				flush(false)
+			}
+		case <-ticker.C:
+			flush(false)
+		}
+	}
+}
+
+func (h *Hook) drop(count int) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.dropped += count
+}
+
+func (h *Hook) send(batch []string) {
+	h.lock.Lock()
+	dropped := h.dropped
+	h.dropped = 0
+	h.lock.Unlock()
+
+	text := strings.Join(batch, "\n")
+	if dropped > 0 {
+		text += fmt.Sprintf("\n(%d entries dropped)", dropped)
+	}
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fail to marshal the webhook payload: %v\n", err)
+		return
+	}
+
+	// The errors are not logged with the logger to not fire the hook again
+	res, err := h.config.HTTPClient.Post(h.config.URL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fail to send the log entries to the webhook: %v\n", err)
+		return
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		fmt.Fprintf(os.Stderr, "fail to send the log entries to the webhook: invalid status code %d\n", res.StatusCode)
+	}
+}
+
+// format formats the entry as "[LEVEL] message key=value" with the fields sorted by key
+func format(entry *logrus.Entry) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "[%s] %s", strings.ToUpper(entry.Level.String()), entry.Message)
+	for _, key := range slices.Sorted(maps.Keys(entry.Data)) {
+		fmt.Fprintf(&text, " %s=%v", key, entry.Data[key])
+	}
+	return text.String()
+}
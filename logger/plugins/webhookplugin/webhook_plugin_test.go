@@ -0,0 +1,138 @@
+package webhookplugin
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Scalingo/go-utils/logger"
+)
+
+type webhookServer struct {
+	*httptest.Server
+	lock  sync.Mutex
+	texts []string
+}
+
+func newWebhookServer(t *testing.T) *webhookServer {
+	t.Helper()
+
+	server := &webhookServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Text string `json:"text"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+
+		server.lock.Lock()
+		defer server.lock.Unlock()
+		server.texts = append(server.texts, payload.Text)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (s *webhookServer) Texts() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.texts...)
+}
+
+func newTestLogger(hook *Hook) *logrus.Logger {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	log.AddHook(hook)
+	return log
+}
+
+func TestHook(t *testing.T) {
+	t.Run("it sends the entries of the configured levels in batches", func(t *testing.T) {
+		server := newWebhookServer(t)
+		hook := NewHook(Config{URL: server.URL, BatchSize: 2, MaxRequestsPerMinute: 6000})
+		log := newTestLogger(hook)
+
+		log.WithField("app", "my-app").Error("deployment failed")
+		log.Info("deployment started")
+		log.WithFields(logrus.Fields{"app": "my-app", "attempt": 2}).Error("deployment failed")
+
+		assert.Eventually(t, func() bool {
+			return len(server.Texts()) == 1
+		}, time.Second, 5*time.Millisecond)
+		hook.Close()
+
+		assert.Equal(t, []string{
+			"[ERROR] deployment failed app=my-app\n[ERROR] deployment failed app=my-app attempt=2",
+		}, server.Texts())
+	})
+
+	t.Run("it sends the pending entries after the flush interval", func(t *testing.T) {
+		server := newWebhookServer(t)
+		hook := NewHook(Config{URL: server.URL, FlushInterval: 10 * time.Millisecond})
+		t.Cleanup(hook.Close)
+		log := newTestLogger(hook)
+
+		log.Error("deployment failed")
+
+		assert.Eventually(t, func() bool {
+			return len(server.Texts()) == 1
+		}, time.Second, 5*time.Millisecond)
+		assert.Equal(t, "[ERROR] deployment failed", server.Texts()[0])
+	})
+
+	t.Run("it drops the entries exceeding the batch size while rate limited", func(t *testing.T) {
+		server := newWebhookServer(t)
+		hook := NewHook(Config{URL: server.URL, BatchSize: 1, MaxRequestsPerMinute: 1})
+		log := newTestLogger(hook)
+
+		log.Error("first")
+		assert.Eventually(t, func() bool {
+			return len(server.Texts()) == 1
+		}, time.Second, 5*time.Millisecond)
+		log.Error("second")
+		log.Error("third")
+		log.Error("fourth")
+		hook.Close()
+
+		texts := server.Texts()
+		require.Len(t, texts, 2)
+		assert.Equal(t, "[ERROR] first", texts[0])
+		assert.Equal(t, "[ERROR] second\n(2 entries dropped)", texts[1])
+	})
+
+	t.Run("it redacts the entries like the output of the logger", func(t *testing.T) {
+		server := newWebhookServer(t)
+		hook := NewHook(Config{URL: server.URL})
+		log := newTestLogger(hook)
+		logger.WithSetRedactedFields([]*logger.RedactionOption{{Field: "*_token"}})(log)
+		logger.WithSecretDetectors(logger.BearerTokenDetector)(log)
+
+		log.WithField("api_token", "secret").Error("request failed with Authorization: Bearer abcdef123456")
+		hook.Close()
+
+		assert.Equal(t, []string{"[ERROR] request failed with Authorization: Bearer [REDACTED] api_token=[REDACTED]"}, server.Texts())
+	})
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("LOGGER_WEBHOOK_URL", "https://hooks.example.com/logs")
+	t.Setenv("LOGGER_WEBHOOK_LEVEL", "warn")
+	t.Setenv("LOGGER_WEBHOOK_BATCH_SIZE", "5")
+	t.Setenv("LOGGER_WEBHOOK_FLUSH_INTERVAL", "1s")
+	t.Setenv("LOGGER_WEBHOOK_MAX_REQUESTS_PER_MINUTE", "10")
+
+	assert.Equal(t, Config{
+		URL:                  "https://hooks.example.com/logs",
+		Levels:               []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel},
+		BatchSize:            5,
+		FlushInterval:        time.Second,
+		MaxRequestsPerMinute: 10,
+	}, ConfigFromEnv())
+}
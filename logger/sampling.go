@@ -0,0 +1,232 @@
+package logger
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SampledMessageField is the field containing the message of the suppressed entries in the summary entries
+const SampledMessageField = "sampled_message"
+
+// SamplingConfig configures the sampling of the repeated entries. Within each period, the first entries with the same
+// level and message are output, then only one every Thereafter entries.
+type SamplingConfig struct {
+	Period time.Duration
+	// First is the number of entries with the same level and message output in each period
+	First int
+	// Thereafter is the sampling rate of the entries after the first ones. If zero, they are all suppressed.
+	Thereafter int
+	// Levels are the sampled levels. If empty, the warning, info, debug and trace levels are sampled.
+	Levels []logrus.Level
+}
+
+// WithSampling samples the repeated entries of the logger. The suppressed entries are neither output nor sent to the
+// hooks. At the end of each period, a summary entry "N similar messages suppressed" is logged for each sampled message.
+// The error entries are only sampled if their level is configured, and the panic and fatal entries are never sampled.
+func WithSampling(config SamplingConfig) Opt {
+	return func(l *logrus.Logger) {
+		l.AddHook(&sampler{
+			config:   config,
+			now:      time.Now,
+			counters: map[samplingKey]*samplingCounter{},
+			hooks:    logrus.LevelHooks{},
+		})
+	}
+}
+
+// withSamplingHooks makes the sampler added by WithSampling, if any, the only hook of the logger. It fires the other
+// hooks for the entries which are not suppressed. It is applied after all the options so that the sampling applies to
+// all the hooks.
+func withSamplingHooks(l *logrus.Logger) {
+	var s *sampler
+	for _, hooks := range l.Hooks {
+		for _, hook := range hooks {
+			if samplingHook, ok := hook.(*sampler); ok {
+				s = samplingHook
+			}
+		}
+	}
+	if s == nil {
+		return
+	}
+
+	for level, hooks := range l.Hooks {
+		for _, hook := range hooks {
+			if _, ok := hook.(*sampler); !ok {
+				s.hooks[level] = append(s.hooks[level], hook)
+			}
+		}
+	}
+	l.ReplaceHooks(logrus.LevelHooks{})
+	l.AddHook(s)
+}
+
+// discardLogger is the logger of the suppressed entries. The sampler replaces the logger of a suppressed entry with it,
+// so that logrus writes the entry to io.Discard instead of the output of the logger.
+var discardLogger = &logrus.Logger{
+	Out:       io.Discard,
+	Formatter: discardFormatter{},
+	Hooks:     logrus.LevelHooks{},
+	Level:     logrus.TraceLevel,
+}
+
+type discardFormatter struct{}
+
+func (discardFormatter) Format(*logrus.Entry) ([]byte, error) {
+	return nil, nil
+}
+
+type samplingKey struct {
+	level   logrus.Level
+	message string
+}
+
+type samplingCounter struct {
+	count      int
+	suppressed int
+	// logger is the logger of the first entry, used to log the summary
+	logger *logrus.Logger
+}
+
+// sampler is the hook deciding whether the entries are suppressed, before firing the other hooks of the logger
+type sampler struct {
+	config SamplingConfig
+	now    func() time.Time
+	hooks  logrus.LevelHooks
+
+	lock        sync.Mutex
+	counters    map[samplingKey]*samplingCounter
+	periodStart time.Time
+	timer       *time.Timer
+}
+
+// samplingSummary is the summary of the suppressed entries of a message
+type samplingSummary struct {
+	key        samplingKey
+	suppressed int
+	logger     *logrus.Logger
+}
+
+func (s *sampler) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (s *sampler) Fire(entry *logrus.Entry) error {
+	if !s.samples(entry) {
+		return s.hooks.Fire(entry.Level, entry)
+	}
+
+	summaries, sampled := s.sample(entry)
+	logSummaries(summaries)
+	if !sampled {
+		entry.Logger = discardLogger
+		return nil
+	}
+	return s.hooks.Fire(entry.Level, entry)
+}
+
+func (s *sampler) samples(entry *logrus.Entry) bool {
+	// The summaries are never sampled
+	if _, ok := entry.Data[SampledMessageField]; ok {
+		return false
+	}
+	if entry.Level <= logrus.FatalLevel {
+		return false
+	}
+	if len(s.config.Levels) == 0 {
+		return entry.Level > logrus.ErrorLevel
+	}
+	return slices.Contains(s.config.Levels, entry.Level)
+}
+
+// sample returns the summaries of the previous period if it is over, and whether the entry must be output
+func (s *sampler) sample(entry *logrus.Entry) ([]samplingSummary, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var summaries []samplingSummary
+	now := s.now()
+	if !s.periodStart.IsZero() && now.Sub(s.periodStart) >= s.config.Period {
+		summaries = s.flushLocked()
+	}
+	if s.periodStart.IsZero() {
+		s.startPeriodLocked(now)
+	}
+
+	key := samplingKey{level: entry.Level, message: entry.Message}
+	counter, ok := s.counters[key]
+	if !ok {
+		counter = &samplingCounter{logger: entry.Logger}
+		s.counters[key] = counter
+	}
+	counter.count++
+
+	if counter.count <= s.config.First {
+		return summaries, true
+	}
+	if s.config.Thereafter > 0 && (counter.count-s.config.First)%s.config.Thereafter == 0 {
+		return summaries, true
+	}
+	counter.suppressed++
+	return summaries, false
+}
+
+// startPeriodLocked starts a period, whose summaries are logged by a timer if no entry is logged after its end. It must
+// be called with the lock held.
+func (s *sampler) startPeriodLocked(now time.Time) {
+	s.periodStart = now
+
+	var timer *time.Timer
+	timer = time.AfterFunc(s.config.Period, func() {
+		s.lock.Lock()
+		// The period may have been flushed by an entry logged while the timer was firing
+		if s.timer != timer {
+			s.lock.Unlock()
+			return
+		}
+		summaries := s.flushLocked()
+		s.lock.Unlock()
+
+		logSummaries(summaries)
+	})
+	s.timer = timer
+}
+
+// flushLocked ends the current period and returns its summaries. It must be called with the lock held.
+func (s *sampler) flushLocked() []samplingSummary {
+	// The summaries are sorted to have a deterministic output
+	keys := slices.SortedFunc(maps.Keys(s.counters), func(a, b samplingKey) int {
+		return cmp.Or(strings.Compare(a.message, b.message), cmp.Compare(a.level, b.level))
+	})
+	var summaries []samplingSummary
+	for _, key := range keys {
+		counter := s.counters[key]
+		if counter.suppressed > 0 {
+			summaries = append(summaries, samplingSummary{key: key, suppressed: counter.suppressed, logger: counter.logger})
+		}
+	}
+
+	clear(s.counters)
+	s.periodStart = time.Time{}
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	return summaries
+}
+
+// logSummaries logs the summaries with the logger of the sampled entries, so that they are sent to the hooks as well
+func logSummaries(summaries []samplingSummary) {
+	for _, summary := range summaries {
+		summary.logger.WithField(SampledMessageField, summary.key.message).
+			Log(summary.key.level, fmt.Sprintf("%d similar messages suppressed", summary.suppressed))
+	}
+}
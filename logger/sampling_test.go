@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithSampling(t *testing.T) {
+	newSampledLogger := func(t *testing.T, config SamplingConfig) (*logrus.Logger, *strings.Builder, *time.Time) {
+		t.Helper()
+
+		var output strings.Builder
+		log := defaultLogrusLogger(t,
+			WithOutput(&output),
+			WithLogLevel(logrus.DebugLevel),
+			WithLogFormatter(&logrus.TextFormatter{DisableTimestamp: true}),
+			WithSampling(config),
+		)
+		sampler, ok := log.Hooks[logrus.InfoLevel][0].(*sampler)
+		require.True(t, ok)
+		now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		sampler.now = func() time.Time { return now }
+		return log, &output, &now
+	}
+
+	t.Run("it outputs the first entries then one every Thereafter", func(t *testing.T) {
+		log, output, _ := newSampledLogger(t, SamplingConfig{Period: time.Hour, First: 2, Thereafter: 3})
+
+		for range 8 {
+			log.Info("BEGIN Message")
+		}
+		log.Info("other message")
+
+		// 2 first entries, then the 5th and the 8th
+		assert.Equal(t, 4, strings.Count(output.String(), "BEGIN Message"))
+		assert.Equal(t, 1, strings.Count(output.String(), "other message"))
+	})
+
+	t.Run("it samples each level separately", func(t *testing.T) {
+		log, output, _ := newSampledLogger(t, SamplingConfig{Period: time.Hour, First: 1})
+
+		log.Info("BEGIN Message")
+		log.Info("BEGIN Message")
+		log.Warn("BEGIN Message")
+
+		assert.Equal(t, 1, strings.Count(output.String(), "level=info"))
+		assert.Equal(t, 1, strings.Count(output.String(), "level=warning"))
+	})
+
+	t.Run("it outputs a summary of the suppressed entries after the period", func(t *testing.T) {
+		log, output, now := newSampledLogger(t, SamplingConfig{Period: time.Hour, First: 1})
+
+		for range 5 {
+			log.Info("BEGIN Message")
+		}
+		for range 3 {
+			log.Debug("END Message")
+		}
+		*now = now.Add(time.Hour)
+		log.Info("BEGIN Message")
+
+		lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+		require.Len(t, lines, 5)
+		assert.Equal(t, `level=info msg="BEGIN Message"`, lines[0])
+		assert.Equal(t, `level=debug msg="END Message"`, lines[1])
+		assert.Equal(t, `level=info msg="4 similar messages suppressed" sampled_message="BEGIN Message"`, lines[2])
+		assert.Equal(t, `level=debug msg="2 similar messages suppressed" sampled_message="END Message"`, lines[3])
+		assert.Equal(t, `level=info msg="BEGIN Message"`, lines[4])
+	})
+
+	t.Run("it only samples the configured levels", func(t *testing.T) {
+		log, output, _ := newSampledLogger(t, SamplingConfig{Period: time.Hour, First: 1, Levels: []logrus.Level{logrus.DebugLevel}})
+
+		for range 3 {
+			log.Debug("debug message")
+			log.Error("error message")
+		}
+
+		assert.Equal(t, 1, strings.Count(output.String(), "debug message"))
+		assert.Equal(t, 3, strings.Count(output.String(), "error message"))
+	})
+
+	t.Run("it does not sample the error entries by default", func(t *testing.T) {
+		log, output, _ := newSampledLogger(t, SamplingConfig{Period: time.Hour, First: 1})
+
+		for range 3 {
+			log.Error("error message")
+		}
+
+		assert.Equal(t, 3, strings.Count(output.String(), "error message"))
+	})
+
+	t.Run("it does not write the suppressed entries to the output", func(t *testing.T) {
+		output := &countingWriter{}
+		log := defaultLogrusLogger(t,
+			WithOutput(output),
+			WithSampling(SamplingConfig{Period: time.Hour, First: 1}),
+		)
+
+		for range 3 {
+			log.Info("BEGIN Message")
+		}
+
+		assert.Equal(t, 1, output.writes)
+	})
+
+	t.Run("it does not send the suppressed entries to the hooks", func(t *testing.T) {
+		hook := test.NewLocal(logrus.New())
+		log := defaultLogrusLogger(t,
+			WithOutput(io.Discard),
+			WithSampling(SamplingConfig{Period: time.Hour, First: 1}),
+			WithHooks([]logrus.Hook{hook}),
+		)
+
+		for range 3 {
+			log.Info("BEGIN Message")
+		}
+
+		require.Len(t, hook.AllEntries(), 1)
+		assert.Equal(t, "BEGIN Message", hook.LastEntry().Message)
+	})
+
+	t.Run("it logs the summaries at the end of the period", func(t *testing.T) {
+		var output safeBuilder
+		hook := test.NewLocal(logrus.New())
+		log := defaultLogrusLogger(t,
+			WithOutput(&output),
+			WithLogFormatter(&logrus.TextFormatter{DisableTimestamp: true}),
+			WithSampling(SamplingConfig{Period: 20 * time.Millisecond, First: 1}),
+			WithHooks([]logrus.Hook{hook}),
+		)
+
+		for range 3 {
+			log.Info("BEGIN Message")
+		}
+
+		assert.Eventually(t, func() bool {
+			return strings.Contains(output.String(), `level=info msg="2 similar messages suppressed" sampled_message="BEGIN Message"`)
+		}, time.Second, 5*time.Millisecond)
+		assert.Equal(t, "2 similar messages suppressed", hook.LastEntry().Message)
+	})
+}
+
+// countingWriter counts the calls to Write
+type countingWriter struct {
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return len(p), nil
+}
+
+// safeBuilder is a strings.Builder safe for concurrent use
+type safeBuilder struct {
+	lock    sync.Mutex
+	builder strings.Builder
+}
+
+func (b *safeBuilder) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.builder.Write(p)
+}
+
+func (b *safeBuilder) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.builder.String()
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	traceIDField = "trace_id"
+	spanIDField  = "span_id"
+)
+
+// WithTraceFields adds the trace_id and span_id fields to the entries of the loggers returned by Get and
+// WithFieldsToCtx when the context carries an active OpenTelemetry span.
+func WithTraceFields() Opt {
+	return func(l *logrus.Logger) {
+		l.Hooks.Add(traceFieldsHook{})
+	}
+}
+
+// traceFieldsHook adds the IDs of the span carried by the context of the entry. Get sets the context of the entry.
+type traceFieldsHook struct{}
+
+func (h traceFieldsHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h traceFieldsHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	spanContext := trace.SpanContextFromContext(entry.Context)
+	if !spanContext.IsValid() {
+		return nil
+	}
+
+	// Fields explicitly set by the caller (e.g. by an HTTP middleware) take precedence
+	if _, ok := entry.Data[traceIDField]; !ok {
+		entry.Data[traceIDField] = spanContext.TraceID().String()
+	}
+	if _, ok := entry.Data[spanIDField]; !ok {
+		entry.Data[spanIDField] = spanContext.SpanID().String()
+	}
+	return nil
+}
+
+// withSpanContext attaches the context to the logger if it carries an active span, so that the hooks can access it
+func withSpanContext(ctx context.Context, log logrus.FieldLogger) logrus.FieldLogger {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return log
+	}
+
+	switch l := log.(type) {
+	case *logrus.Entry:
+		return l.WithContext(ctx)
+	case *logrus.Logger:
+		return l.WithContext(ctx)
+	default:
+		return log
+	}
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithTraceFields(t *testing.T) {
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	contextWithSpan := func(ctx context.Context) context.Context {
+		return trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled,
+		}))
+	}
+
+	t.Run("it adds the trace fields when the context carries a span", func(t *testing.T) {
+		hook := TestLastEntryHook{}
+		ctx := ToCtx(t.Context(), Default(WithTraceFields(), WithHooks([]logrus.Hook{&hook})))
+
+		Get(contextWithSpan(ctx)).Info("test")
+
+		require.NotNil(t, hook.lastEntry)
+		assert.Equal(t, traceID.String(), hook.lastEntry.Data["trace_id"])
+		assert.Equal(t, spanID.String(), hook.lastEntry.Data["span_id"])
+	})
+
+	t.Run("it adds the trace fields to the logger stored by WithFieldsToCtx", func(t *testing.T) {
+		hook := TestLastEntryHook{}
+		ctx := ToCtx(t.Context(), Default(WithTraceFields(), WithHooks([]logrus.Hook{&hook})))
+
+		_, log := WithFieldsToCtx(contextWithSpan(ctx), logrus.Fields{"app": "my-app"})
+		log.Info("test")
+
+		require.NotNil(t, hook.lastEntry)
+		assert.Equal(t, "my-app", hook.lastEntry.Data["app"])
+		assert.Equal(t, traceID.String(), hook.lastEntry.Data["trace_id"])
+		assert.Equal(t, spanID.String(), hook.lastEntry.Data["span_id"])
+	})
+
+	t.Run("it does not override the trace fields set explicitly", func(t *testing.T) {
+		hook := TestLastEntryHook{}
+		ctx := ToCtx(t.Context(), Default(WithTraceFields(), WithHooks([]logrus.Hook{&hook})))
+
+		Get(contextWithSpan(ctx)).WithField("trace_id", "explicit").Info("test")
+
+		require.NotNil(t, hook.lastEntry)
+		assert.Equal(t, "explicit", hook.lastEntry.Data["trace_id"])
+	})
+
+	t.Run("it does not add the trace fields without span", func(t *testing.T) {
+		hook := TestLastEntryHook{}
+		ctx := ToCtx(t.Context(), Default(WithTraceFields(), WithHooks([]logrus.Hook{&hook})))
+
+		Get(ctx).Info("test")
+
+		require.NotNil(t, hook.lastEntry)
+		assert.NotContains(t, hook.lastEntry.Data, "trace_id")
+		assert.NotContains(t, hook.lastEntry.Data, "span_id")
+	})
+
+	t.Run("it does not add the trace fields without the option", func(t *testing.T) {
+		hook := TestLastEntryHook{}
+		ctx := ToCtx(t.Context(), Default(WithHooks([]logrus.Hook{&hook})))
+
+		Get(contextWithSpan(ctx)).Info("test")
+
+		require.NotNil(t, hook.lastEntry)
+		assert.NotContains(t, hook.lastEntry.Data, "trace_id")
+	})
+}
@@ -52,6 +52,15 @@ type Validable interface {
 
 var _ Validable = &Base{}
 
+var structValidator func(ctx context.Context, doc any) error
+
+// SetStructValidator sets a validator of the struct tags of the documents, run by Create, Save and Update before their
+// Validate method, e.g. `document.SetStructValidator(validation.Struct)` with the package
+// github.com/Scalingo/go-utils/errors/v3/validation. The validation errors of both are merged.
+func SetStructValidator(validator func(ctx context.Context, doc any) error) {
+	structValidator = validator
+}
+
 // Create inserts the document in the database, returns an error if document
 // already exists and set CreatedAt timestamp
 func Create(ctx context.Context, collectionName string, doc document) error {
@@ -97,9 +106,9 @@ func Update(ctx context.Context, collectionName string, update bson.M, doc docum
 }
 
 func save(ctx context.Context, collectionName string, doc document, saveFunc func(context.Context, string, document) error) error {
-	validationErrors := doc.Validate(ctx)
-	if validationErrors != nil {
-		return validationErrors
+	err := validate(ctx, doc)
+	if err != nil {
+		return err
 	}
 
 	doc.ensureID()
@@ -114,6 +123,31 @@ func save(ctx context.Context, collectionName string, doc document, saveFunc fun
 	return saveFunc(ctx, collectionName, doc)
 }
 
+func validate(ctx context.Context, doc document) error {
+	if structValidator == nil {
+		return doc.Validate(ctx)
+	}
+
+	structErr := structValidator(ctx, doc)
+	var structValidationErr *errors.ValidationErrors
+	if structErr != nil && !errors.As(structErr, &structValidationErr) {
+		return errors.Wrap(ctx, structErr, "validate the struct tags of the document")
+	}
+
+	docErr := doc.Validate(ctx)
+	if structErr == nil {
+		return docErr
+	}
+	if docErr == nil {
+		return structErr
+	}
+	var docValidationErr *errors.ValidationErrors
+	if !errors.As(docErr, &docValidationErr) {
+		return docErr
+	}
+	return errors.NewValidationErrorsBuilder().Merge(structValidationErr).Merge(docValidationErr).Build()
+}
+
 // Destroy really deletes
 func Destroy(ctx context.Context, collectionName string, doc destroyable) error {
 	return doc.destroy(ctx, collectionName)
@@ -77,3 +77,56 @@ func TestDocument_Create(t *testing.T) {
 		})
 	})
 }
+
+func TestDocument_validate(t *testing.T) {
+	ctx := context.Background()
+	t.Cleanup(func() {
+		SetStructValidator(nil)
+	})
+
+	tests := map[string]struct {
+		structErr      error
+		valid          bool
+		expectedErrors map[string][]string
+		expectedErr    string
+	}{
+		"without errors": {
+			valid: true,
+		},
+		"with struct validation errors": {
+			structErr:      errors.NewValidationErrorsBuilder().Set("name", "should not be empty").Build(),
+			valid:          true,
+			expectedErrors: map[string][]string{"name": {"should not be empty"}},
+		},
+		"with struct and document validation errors": {
+			structErr:      errors.NewValidationErrorsBuilder().Set("name", "should not be empty").Build(),
+			expectedErrors: map[string][]string{"name": {"should not be empty"}, "valid": {"must be true"}},
+		},
+		"with a struct validator failure": {
+			structErr:   errors.New(ctx, "unknown validation rule"),
+			valid:       true,
+			expectedErr: "validate the struct tags of the document: unknown validation rule",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			SetStructValidator(func(context.Context, any) error {
+				return test.structErr
+			})
+
+			err := validate(ctx, buildValidatedDocument(test.valid))
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+				return
+			}
+			if test.expectedErrors == nil {
+				require.NoError(t, err)
+				return
+			}
+			var validationErr *errors.ValidationErrors
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, test.expectedErrors, validationErr.Errors)
+		})
+	}
+}
@@ -24,6 +24,9 @@ const (
 	// defaultChannel is the name of the channel we're using when we want the
 	// message to be receive only by 1 consumer, but no matter which one
 	defaultChannel = "default"
+
+	// logComponent is the component of the consumer loggers, whose level can be overridden with LOGGER_LEVELS
+	logComponent = "nsqconsumer"
 )
 
 var (
@@ -232,7 +235,7 @@ func New(opts ConsumerOpts) (Consumer, error) {
 }
 
 func (c *nsqConsumer) Start(ctx context.Context) func() {
-	c.logger = logger.Get(ctx).WithFields(logrus.Fields{
+	c.logger = logger.WithComponent(logger.Get(ctx), logComponent).WithFields(logrus.Fields{
 		"topic":   c.Topic,
 		"channel": c.Channel,
 	})
@@ -318,13 +321,19 @@ func (c *nsqConsumer) nsqHandler(message *nsq.Message) (err error) {
 
 	// We want to create a new dedicated logger for the NSQ message handling.
 	// That way we distinguish between the logger during normal operation, and the error logger (named `errLogger`) found when unwrapping the error raised during message handling.
-	msgLogger := logger.Default()
+	msgLogger := logger.WithComponent(logger.Default(), logComponent)
 	ctx = logger.ToCtx(context.Background(), msgLogger)
-	ctx, msgLogger = logger.WithFieldsToCtx(ctx, logrus.Fields{
+	fields := logrus.Fields{
 		"message_id":   fmt.Sprintf("%s", message.ID),
 		"message_type": msg.Type,
-		"request_id":   msg.RequestID,
-	})
+	}
+	// The request ID of the message flows to the HTTP requests and messages sent by the handler (see httpclient and nsqproducer)
+	if msg.RequestID != "" {
+		//nolint:revive,staticcheck // The "request_id" key is shared with other modules and repositories as a string.
+		ctx = context.WithValue(ctx, "request_id", msg.RequestID)
+		fields["request_id"] = msg.RequestID
+	}
+	ctx, msgLogger = logger.WithFieldsToCtx(ctx, fields)
 
 	if msg.At != 0 {
 		now := time.Now().Unix()
@@ -4,7 +4,7 @@ go 1.25.0
 
 require (
 	github.com/Scalingo/go-utils/errors/v3 v3.2.1
-	github.com/Scalingo/go-utils/logger v1.12.2
+	github.com/Scalingo/go-utils/logger v1.13.0
 	github.com/Scalingo/go-utils/nsqproducer v1.4.1
 	github.com/Scalingo/go-utils/otel v0.10.1
 	github.com/nsqio/go-nsq v1.1.0
@@ -3,6 +3,7 @@ package nsqproducer
 import (
 	"context"
 	"encoding/json"
+	"maps"
 	"time"
 
 	"github.com/gofrs/uuid/v5"
@@ -168,7 +169,7 @@ func (p *NsqProducer) DeferredPublish(ctx context.Context, topic string, delay i
 
 func (p *NsqProducer) requestID(ctx context.Context) (string, error) {
 	reqid, ok := ctx.Value("request_id").(string)
-	if !ok {
+	if !ok || reqid == "" {
 		uuid, err := uuid.NewV4()
 		if err != nil {
 			return "", errors.Wrap(ctx, err, "generate UUID v4")
@@ -187,7 +188,12 @@ func (p *NsqProducer) log(ctx context.Context, message NsqMessageSerialize, fiel
 		return
 	}
 
-	logger := p.logger(ctx).WithFields(fields)
+	// The request ID may have been generated for this message, it is logged to be able to follow it in the consumers.
+	// The fields of the caller are copied not to modify them.
+	logFields := make(logrus.Fields, len(fields)+1)
+	maps.Copy(logFields, fields)
+	logFields["request_id"] = message.RequestID
+	logger := p.logger(ctx).WithFields(logFields)
 
 	if logger.Level == logrus.DebugLevel {
 		logger.WithFields(logrus.Fields{"message_type": message.Type, "message_payload": message.Payload}).Debug("publish message")
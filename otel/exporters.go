@@ -0,0 +1,102 @@
+package otel
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/Scalingo/go-utils/errors/v3"
+)
+
+// Values of Config.ExporterType
+const (
+	ExporterTypeGRPC   = "grpc"
+	ExporterTypeHTTP   = "http"
+	ExporterTypeStdout = "stdout"
+	ExporterTypeFile   = "file"
+	// ExporterTypePrometheus exposes the metrics to be scraped by Prometheus. It does not export any span.
+	ExporterTypePrometheus = "prometheus"
+)
+
+// Values of OTEL_EXPORTER_OTLP_PROTOCOL
+// https://opentelemetry.io/docs/specs/otel/protocol/exporter/#specify-protocol
+const (
+	otlpProtocolGRPC         = "grpc"
+	otlpProtocolHTTPProtobuf = "http/protobuf"
+	otlpProtocolHTTPJSON     = "http/json"
+)
+
+// selectExporterType returns the exporter to use according to the configuration. The exporter type is "stdout" in debug
+// mode. Otherwise, if OTEL_EXPORTER_OTLP_PROTOCOL is set, it takes precedence over the OTLP exporter type.
+func selectExporterType(ctx context.Context, cfg *Config) (string, error) {
+	if cfg.Debug {
+		return ExporterTypeStdout, nil
+	}
+
+	switch cfg.ExporterType {
+	case ExporterTypeStdout, ExporterTypeFile, ExporterTypePrometheus:
+		return cfg.ExporterType, nil
+	case ExporterTypeGRPC, ExporterTypeHTTP, otlpProtocolHTTPProtobuf:
+	default:
+		return "", errors.Newf(ctx, "invalid exporter type '%s'", cfg.ExporterType)
+	}
+
+	protocol := cfg.ExporterOtlpProtocol
+	if protocol == "" {
+		protocol = cfg.ExporterType
+	}
+
+	switch protocol {
+	case otlpProtocolGRPC:
+		return ExporterTypeGRPC, nil
+	case ExporterTypeHTTP, otlpProtocolHTTPProtobuf:
+		return ExporterTypeHTTP, nil
+	case otlpProtocolHTTPJSON:
+		return "", errors.New(ctx, "OTLP protocol 'http/json' is not supported")
+	default:
+		return "", errors.Newf(ctx, "invalid OTLP protocol '%s'", protocol)
+	}
+}
+
+func isOTLPExporterType(exporterType string) bool {
+	return exporterType == ExporterTypeGRPC || exporterType == ExporterTypeHTTP
+}
+
+// exporterOutput is the destination of the stdout and file exporters. The metrics and traces exporters share the same
+// output, hence the writes are serialized so that each exported batch remains on its own line.
+type exporterOutput struct {
+	lock   sync.Mutex
+	writer io.Writer
+	closer io.Closer
+}
+
+// newExporterOutput returns the output of the stdout and file exporters. It returns nil for the OTLP exporters.
+func newExporterOutput(ctx context.Context, cfg *Config, exporterType string) (*exporterOutput, error) {
+	switch exporterType {
+	case ExporterTypeStdout:
+		return &exporterOutput{writer: os.Stdout}, nil
+	case ExporterTypeFile:
+		file, err := os.OpenFile(cfg.ExporterFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, errors.Wrapf(ctx, err, "open exporter file '%s'", cfg.ExporterFilePath)
+		}
+		return &exporterOutput{writer: file, closer: file}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (o *exporterOutput) Write(p []byte) (int, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	return o.writer.Write(p)
+}
+
+func (o *exporterOutput) Close() error {
+	if o == nil || o.closer == nil {
+		return nil
+	}
+	return o.closer.Close()
+}
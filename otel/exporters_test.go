@@ -0,0 +1,122 @@
+package otel
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otelsdk "go.opentelemetry.io/otel"
+)
+
+func TestSelectExporterType(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        Config
+		expectType string
+		expectErr  string
+	}{
+		{
+			name:       "debug mode uses the stdout exporter",
+			cfg:        Config{Debug: true, ExporterType: "grpc"},
+			expectType: ExporterTypeStdout,
+		}, {
+			name:       "grpc exporter",
+			cfg:        Config{ExporterType: "grpc"},
+			expectType: ExporterTypeGRPC,
+		}, {
+			name:       "http exporter",
+			cfg:        Config{ExporterType: "http"},
+			expectType: ExporterTypeHTTP,
+		}, {
+			name:       "http/protobuf exporter",
+			cfg:        Config{ExporterType: "http/protobuf"},
+			expectType: ExporterTypeHTTP,
+		}, {
+			name:       "OTLP protocol takes precedence over the OTLP exporter type",
+			cfg:        Config{ExporterType: "grpc", ExporterOtlpProtocol: "http/protobuf"},
+			expectType: ExporterTypeHTTP,
+		}, {
+			name:       "OTLP protocol does not override the file exporter",
+			cfg:        Config{ExporterType: "file", ExporterOtlpProtocol: "grpc"},
+			expectType: ExporterTypeFile,
+		}, {
+			name:       "stdout exporter",
+			cfg:        Config{ExporterType: "stdout"},
+			expectType: ExporterTypeStdout,
+		}, {
+			name:      "http/json is not supported",
+			cfg:       Config{ExporterType: "grpc", ExporterOtlpProtocol: "http/json"},
+			expectErr: "OTLP protocol 'http/json' is not supported",
+		}, {
+			name:      "invalid OTLP protocol",
+			cfg:       Config{ExporterType: "grpc", ExporterOtlpProtocol: "udp"},
+			expectErr: "invalid OTLP protocol 'udp'",
+		}, {
+			name:      "invalid exporter type",
+			cfg:       Config{ExporterType: "carrier-pigeon"},
+			expectErr: "invalid exporter type 'carrier-pigeon'",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			exporterType, err := selectExporterType(t.Context(), &test.cfg)
+			if test.expectErr != "" {
+				require.ErrorContains(t, err, test.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectType, exporterType)
+		})
+	}
+}
+
+func TestInit_FileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "otel.jsonl")
+	t.Setenv("GO_ENV", "production")
+	t.Setenv("OTEL_SERVICE_NAME", "test")
+	t.Setenv("OTEL_EXPORTER_TYPE", "file")
+	t.Setenv("OTEL_EXPORTER_FILE_PATH", path)
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+
+	// No OTLP endpoint nor TLS configuration is required with the file exporter
+	shutdown := Init(t.Context())
+
+	counter, err := otelsdk.Meter("test").Int64Counter("test.counter")
+	require.NoError(t, err)
+	counter.Add(t.Context(), 1)
+	_, span := otelsdk.Tracer("test").Start(t.Context(), "test-span")
+	span.End()
+
+	require.NoError(t, shutdown())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var lines []map[string]any
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.NoError(t, scanner.Err())
+
+	var spanNames, scopeMetrics []any
+	for _, line := range lines {
+		if name, ok := line["Name"]; ok {
+			spanNames = append(spanNames, name)
+		}
+		if metrics, ok := line["ScopeMetrics"]; ok {
+			scopeMetrics = append(scopeMetrics, metrics)
+		}
+	}
+	assert.Equal(t, []any{"test-span"}, spanNames)
+	assert.NotEmpty(t, scopeMetrics)
+}
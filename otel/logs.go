@@ -0,0 +1,273 @@
+package otel
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/Scalingo/go-utils/errors/v3"
+	"github.com/Scalingo/go-utils/logger"
+)
+
+// logsExporterNone is the value of OTEL_LOGS_EXPORTER disabling the export of logs
+const logsExporterNone = "none"
+
+// newLoggerProvider creates a LoggerProvider exporting log records in batch with the exporter configured in the
+// environment. It returns a nil LoggerProvider if logs export is disabled or if the exporter does not support logs.
+func newLoggerProvider(ctx context.Context, cfg *Config, res *resource.Resource, exporterType string, output *exporterOutput) (*sdklog.LoggerProvider, error) {
+	if cfg.LogsExporter == logsExporterNone || exporterType == ExporterTypePrometheus {
+		return nil, nil
+	}
+
+	logsExporter, err := newLogsExporter(ctx, cfg, exporterType, output)
+	if err != nil {
+		return nil, errors.Wrap(ctx, err, "create logs exporter")
+	}
+
+	// The batch log record processor is configured with the OTEL_BLRP_* environment variables
+	return sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(logsExporter)),
+		sdklog.WithResource(res),
+	), nil
+}
+
+func newLogsExporter(ctx context.Context, cfg *Config, exporterType string, output *exporterOutput) (sdklog.Exporter, error) {
+	enforceTLSByDefault := isTLSEnforced()
+
+	var tlsConfig *tls.Config
+	var err error
+	if enforceTLSByDefault && isOTLPExporterType(exporterType) {
+		tlsConfig, err = setTLSConfig(ctx, cfg)
+		if err != nil {
+			return nil, errors.Wrap(ctx, err, "set TLS configuration")
+		}
+	}
+
+	switch exporterType {
+	case ExporterTypeStdout:
+		opts := []stdoutlog.Option{stdoutlog.WithWriter(output)}
+		if cfg.DebugPrettyPrint {
+			opts = append(opts, stdoutlog.WithPrettyPrint())
+		}
+		exporter, err := stdoutlog.New(opts...)
+		if err != nil {
+			return nil, errors.Wrap(ctx, err, "create stdout exporter")
+		}
+		return exporter, nil
+	case ExporterTypeFile:
+		// Without pretty print, each log record is written as a single JSON line
+		exporter, err := stdoutlog.New(stdoutlog.WithWriter(output))
+		if err != nil {
+			return nil, errors.Wrap(ctx, err, "create file exporter")
+		}
+		return exporter, nil
+	case ExporterTypeHTTP:
+		if enforceTLSByDefault {
+			exporter, err := otlploghttp.New(
+				ctx, otlploghttp.WithTLSClientConfig(tlsConfig),
+			)
+			if err != nil {
+				return nil, errors.Wrap(ctx, err, "create OTLP HTTPs exporter")
+			}
+			return exporter, nil
+		}
+		exporter, err := otlploghttp.New(ctx)
+		if err != nil {
+			return nil, errors.Wrap(ctx, err, "create OTLP HTTP exporter")
+		}
+		return exporter, nil
+	case ExporterTypeGRPC:
+		if enforceTLSByDefault {
+			exporter, err := otlploggrpc.New(
+				ctx, otlploggrpc.WithDialOption(
+					grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+				),
+			)
+			if err != nil {
+				return nil, errors.Wrap(ctx, err, "create OTLP gRPC (TLS) exporter")
+			}
+			return exporter, nil
+		}
+		exporter, err := otlploggrpc.New(ctx)
+		if err != nil {
+			return nil, errors.Wrap(ctx, err, "create OTLP gRPC exporter")
+		}
+		return exporter, nil
+	default:
+		return nil, errors.New(ctx, "invalid exporter type")
+	}
+}
+
+// LogsPlugin is a logger plugin sending the logrus entries to the global LoggerProvider configured by Init. It must
+// be registered before the creation of the loggers with logger.Default. The entries logged before Init are dropped.
+type LogsPlugin struct{}
+
+// RegisterLogsPlugin registers the LogsPlugin to the logger library
+func RegisterLogsPlugin() {
+	logger.Plugins().RegisterPlugin(LogsPlugin{})
+}
+
+func (p LogsPlugin) Name() string {
+	return "otel"
+}
+
+func (p LogsPlugin) Hook() (bool, logrus.Hook) {
+	return true, logsHook{}
+}
+
+// logsHook converts the logrus entries to OpenTelemetry log records
+type logsHook struct{}
+
+func (h logsHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h logsHook) Fire(entry *logrus.Entry) error {
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx = contextWithLoggedSpan(ctx, entry.Data)
+
+	otelLogger := global.GetLoggerProvider().Logger(instrumentationName)
+	severity := logSeverity(entry.Level)
+	if !otelLogger.Enabled(ctx, otellog.EnabledParameters{Severity: severity}) {
+		return nil
+	}
+	// The entries are exported with the fields and message redacted like in the output
+	entry = logger.RedactingFormatterOf(entry.Logger).Redact(entry)
+
+	var record otellog.Record
+	record.SetTimestamp(entry.Time)
+	record.SetObservedTimestamp(time.Now())
+	record.SetSeverity(severity)
+	record.SetSeverityText(entry.Level.String())
+	record.SetBody(otellog.StringValue(entry.Message))
+
+	attributes := make([]otellog.KeyValue, 0, len(entry.Data))
+	for key, value := range entry.Data {
+		if err, ok := value.(error); ok && key == logrus.ErrorKey {
+			record.SetErr(err)
+			continue
+		}
+		attributes = append(attributes, otellog.KeyValue{Key: key, Value: logValue(value)})
+	}
+	record.AddAttributes(attributes...)
+
+	otelLogger.Emit(ctx, record)
+	return nil
+}
+
+// contextWithLoggedSpan returns a context carrying the span of the trace_id and span_id fields, if the context does
+// not already carry a span. These fields are added by the HTTP middlewares of this repository.
+func contextWithLoggedSpan(ctx context.Context, fields logrus.Fields) context.Context {
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	traceIDField, _ := fields["trace_id"].(string)
+	spanIDField, _ := fields["span_id"].(string)
+	traceID, err := trace.TraceIDFromHex(traceIDField)
+	if err != nil {
+		return ctx
+	}
+	spanID, err := trace.SpanIDFromHex(spanIDField)
+	if err != nil {
+		return ctx
+	}
+	return trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Remote:  true,
+	}))
+}
+
+// logSeverity maps the logrus levels to the OpenTelemetry severity numbers
+// https://opentelemetry.io/docs/specs/otel/logs/data-model-appendix/#appendix-b-severitynumber-example-mappings
+func logSeverity(level logrus.Level) otellog.Severity {
+	switch level {
+	case logrus.TraceLevel:
+		return otellog.SeverityTrace
+	case logrus.DebugLevel:
+		return otellog.SeverityDebug
+	case logrus.InfoLevel:
+		return otellog.SeverityInfo
+	case logrus.WarnLevel:
+		return otellog.SeverityWarn
+	case logrus.ErrorLevel:
+		return otellog.SeverityError
+	case logrus.FatalLevel:
+		return otellog.SeverityFatal
+	case logrus.PanicLevel:
+		return otellog.SeverityFatal4
+	default:
+		return otellog.SeverityUndefined
+	}
+}
+
+func logValue(value any) otellog.Value {
+	switch v := value.(type) {
+	case string:
+		return otellog.StringValue(v)
+	case bool:
+		return otellog.BoolValue(v)
+	case int:
+		return otellog.IntValue(v)
+	case int8:
+		return otellog.Int64Value(int64(v))
+	case int16:
+		return otellog.Int64Value(int64(v))
+	case int32:
+		return otellog.Int64Value(int64(v))
+	case int64:
+		return otellog.Int64Value(v)
+	case uint8:
+		return otellog.Int64Value(int64(v))
+	case uint16:
+		return otellog.Int64Value(int64(v))
+	case uint32:
+		return otellog.Int64Value(int64(v))
+	case uint:
+		return uint64LogValue(uint64(v))
+	case uint64:
+		return uint64LogValue(v)
+	case float32:
+		return otellog.Float64Value(float64(v))
+	case float64:
+		return otellog.Float64Value(v)
+	case []byte:
+		return otellog.BytesValue(v)
+	case time.Time:
+		return otellog.StringValue(v.Format(time.RFC3339Nano))
+	case error:
+		return otellog.StringValue(v.Error())
+	case fmt.Stringer:
+		return otellog.StringValue(v.String())
+	default:
+		return otellog.StringValue(fmt.Sprint(v))
+	}
+}
+
+// uint64LogValue returns an int64 value, or a string value if it overflows an int64 as the log values have no unsigned
+// type
+func uint64LogValue(v uint64) otellog.Value {
+	if v > math.MaxInt64 {
+		return otellog.StringValue(strconv.FormatUint(v, 10))
+	}
+	return otellog.Int64Value(int64(v))
+}
@@ -0,0 +1,204 @@
+package otel
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Scalingo/go-utils/errors/v3"
+	"github.com/Scalingo/go-utils/logger"
+)
+
+type inMemoryLogsExporter struct {
+	lock    sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *inMemoryLogsExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for _, record := range records {
+		e.records = append(e.records, record.Clone())
+	}
+	return nil
+}
+
+func (e *inMemoryLogsExporter) Shutdown(context.Context) error   { return nil }
+func (e *inMemoryLogsExporter) ForceFlush(context.Context) error { return nil }
+
+func initInMemoryLoggerProvider(t *testing.T) *inMemoryLogsExporter {
+	exporter := &inMemoryLogsExporter{}
+	loggerProvider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+
+	initialLoggerProvider := global.GetLoggerProvider()
+	global.SetLoggerProvider(loggerProvider)
+	t.Cleanup(func() {
+		global.SetLoggerProvider(initialLoggerProvider)
+	})
+	return exporter
+}
+
+func recordAttributes(record sdklog.Record) map[string]otellog.Value {
+	attributes := map[string]otellog.Value{}
+	record.WalkAttributes(func(kv otellog.KeyValue) bool {
+		attributes[kv.Key] = kv.Value
+		return true
+	})
+	return attributes
+}
+
+func TestLogsHook_Fire(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+
+	newLogger := func() *logrus.Logger {
+		log := logrus.New()
+		log.SetLevel(logrus.DebugLevel)
+		log.Hooks.Add(logsHook{})
+		return log
+	}
+
+	t.Run("it converts the entry to a log record", func(t *testing.T) {
+		exporter := initInMemoryLoggerProvider(t)
+
+		newLogger().WithFields(logrus.Fields{
+			"app":      "my-app",
+			"attempt":  2,
+			"duration": 1.5,
+			"success":  false,
+		}).WithError(errors.New(t.Context(), "deployment failed")).Warn("Deployment error")
+
+		require.Len(t, exporter.records, 1)
+		record := exporter.records[0]
+		assert.Equal(t, "Deployment error", record.Body().AsString())
+		assert.Equal(t, otellog.SeverityWarn, record.Severity())
+		assert.Equal(t, "warning", record.SeverityText())
+		assert.False(t, record.Timestamp().IsZero())
+
+		attributes := recordAttributes(record)
+		assert.Equal(t, "my-app", attributes["app"].AsString())
+		assert.Equal(t, int64(2), attributes["attempt"].AsInt64())
+		assert.InDelta(t, 1.5, attributes["duration"].AsFloat64(), 1e-9)
+		assert.False(t, attributes["success"].AsBool())
+		assert.Equal(t, "deployment failed", attributes["exception.message"].AsString())
+	})
+
+	t.Run("it converts the unsigned integers", func(t *testing.T) {
+		exporter := initInMemoryLoggerProvider(t)
+
+		newLogger().WithFields(logrus.Fields{
+			"size":  uint(42),
+			"total": uint64(math.MaxUint64),
+		}).Info("Sizes")
+
+		require.Len(t, exporter.records, 1)
+		attributes := recordAttributes(exporter.records[0])
+		assert.Equal(t, int64(42), attributes["size"].AsInt64())
+		assert.Equal(t, "18446744073709551615", attributes["total"].AsString())
+	})
+
+	t.Run("it redacts the entry like the output", func(t *testing.T) {
+		exporter := initInMemoryLoggerProvider(t)
+		log := newLogger()
+		logger.WithSetRedactedFields([]*logger.RedactionOption{{Field: "*_token"}})(log)
+
+		log.WithField("api_token", "secret").Info("Authenticated")
+
+		require.Len(t, exporter.records, 1)
+		assert.Equal(t, "[REDACTED]", recordAttributes(exporter.records[0])["api_token"].AsString())
+	})
+
+	t.Run("it sets the span of the entry context", func(t *testing.T) {
+		exporter := initInMemoryLoggerProvider(t)
+		ctx := trace.ContextWithSpanContext(t.Context(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled,
+		}))
+
+		newLogger().WithContext(ctx).Info("In a span")
+
+		require.Len(t, exporter.records, 1)
+		assert.Equal(t, traceID, exporter.records[0].TraceID())
+		assert.Equal(t, spanID, exporter.records[0].SpanID())
+	})
+
+	t.Run("it sets the span of the trace_id and span_id fields", func(t *testing.T) {
+		exporter := initInMemoryLoggerProvider(t)
+
+		newLogger().WithFields(logrus.Fields{
+			"trace_id": traceID.String(),
+			"span_id":  spanID.String(),
+		}).Debug("In a span")
+
+		require.Len(t, exporter.records, 1)
+		assert.Equal(t, traceID, exporter.records[0].TraceID())
+		assert.Equal(t, spanID, exporter.records[0].SpanID())
+	})
+}
+
+func TestLogSeverity(t *testing.T) {
+	tests := map[logrus.Level]otellog.Severity{
+		logrus.TraceLevel: otellog.SeverityTrace,
+		logrus.DebugLevel: otellog.SeverityDebug,
+		logrus.InfoLevel:  otellog.SeverityInfo,
+		logrus.WarnLevel:  otellog.SeverityWarn,
+		logrus.ErrorLevel: otellog.SeverityError,
+		logrus.FatalLevel: otellog.SeverityFatal,
+		logrus.PanicLevel: otellog.SeverityFatal4,
+	}
+	for level, severity := range tests {
+		assert.Equal(t, severity, logSeverity(level), level.String())
+	}
+}
+
+func TestInit_LoggerProvider(t *testing.T) {
+	minimalValidEnv := map[string]string{
+		"GO_ENV":                             "test",
+		"OTEL_SERVICE_NAME":                  "test",
+		"OTEL_EXPORTER_OTLP_ENDPOINT":        "http://localhost:4317",
+		"OTEL_EXPORTER_OTLP_METRICS_TIMEOUT": "1",
+		"OTEL_EXPORTER_OTLP_TRACES_TIMEOUT":  "1",
+		"OTEL_EXPORTER_OTLP_LOGS_TIMEOUT":    "1",
+	}
+
+	t.Run("it should set a SDK logger provider", func(t *testing.T) {
+		for k, v := range minimalValidEnv {
+			t.Setenv(k, v)
+		}
+		t.Setenv("OTEL_LOGS_EXPORTER", "otlp")
+		initialLoggerProvider := global.GetLoggerProvider()
+		t.Cleanup(func() {
+			global.SetLoggerProvider(initialLoggerProvider)
+		})
+
+		shutdown := Init(t.Context())
+		t.Cleanup(func() {
+			require.NoError(t, shutdown())
+		})
+
+		assert.IsType(t, &sdklog.LoggerProvider{}, global.GetLoggerProvider())
+	})
+
+	t.Run("it should not replace the logger provider if logs are disabled", func(t *testing.T) {
+		for k, v := range minimalValidEnv {
+			t.Setenv(k, v)
+		}
+		t.Setenv("OTEL_LOGS_EXPORTER", "none")
+
+		initialLoggerProvider := global.GetLoggerProvider()
+		shutdown := Init(t.Context())
+		t.Cleanup(func() {
+			require.NoError(t, shutdown())
+		})
+
+		assert.Same(t, initialLoggerProvider, global.GetLoggerProvider())
+	})
+}
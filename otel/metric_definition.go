@@ -0,0 +1,373 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	otelsdk "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// attributeTagName is the struct tag defining the attribute name of a field in the attributes struct of a metric
+// definition. The "omitempty" option omits the attribute if the field has an empty value, other options are rejected.
+//
+//	type DeploymentAttributes struct {
+//		AppID  string `otel:"scalingo.app.id"`
+//		Status string `otel:"deployment.status,omitempty"`
+//	}
+const attributeTagName = "otel"
+
+// attributeNamePattern is the allowed format of an attribute name
+// https://opentelemetry.io/docs/specs/semconv/general/naming/
+var attributeNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.\-]*$`)
+
+// MetricOpt is a function-option type for the metric definitions (NewCounter, NewHistogram...)
+type MetricOpt func(*metricOptions)
+
+type metricOptions struct {
+	meterName   string
+	description string
+	unit        string
+	buckets     []float64
+}
+
+// WithMetricDescription sets the description of the instrument
+func WithMetricDescription(description string) MetricOpt {
+	return func(o *metricOptions) {
+		o.description = description
+	}
+}
+
+// WithMetricUnit sets the unit of the instrument, following the UCUM case sensitive syntax (e.g. "s", "By", "{request}")
+func WithMetricUnit(unit string) MetricOpt {
+	return func(o *metricOptions) {
+		o.unit = unit
+	}
+}
+
+// WithMetricMeterName sets the name of the meter creating the instrument. By default, it is the import path of the
+// package declaring the attributes struct.
+func WithMetricMeterName(name string) MetricOpt {
+	return func(o *metricOptions) {
+		o.meterName = name
+	}
+}
+
+// WithMetricBuckets sets the explicit bucket boundaries of a histogram
+func WithMetricBuckets(buckets ...float64) MetricOpt {
+	return func(o *metricOptions) {
+		o.buckets = buckets
+	}
+}
+
+// Counter is a monotonic int64 counter whose attributes are described by the Attrs struct
+type Counter[Attrs any] struct {
+	definition *metricDefinition[Attrs, metric.Int64Counter]
+}
+
+// NewCounter declares a monotonic int64 counter. The instrument is lazily created from the global MeterProvider on
+// first use. NewCounter is meant to be called at package initialization: it panics if the Attrs struct is invalid.
+func NewCounter[Attrs any](name string, opts ...MetricOpt) *Counter[Attrs] {
+	return &Counter[Attrs]{
+		definition: newMetricDefinition[Attrs](name, opts, func(meter metric.Meter, name string, o metricOptions) (metric.Int64Counter, error) {
+			return meter.Int64Counter(name, metric.WithDescription(o.description), metric.WithUnit(o.unit))
+		}),
+	}
+}
+
+// Add increments the counter for the given attributes
+func (c *Counter[Attrs]) Add(ctx context.Context, incr int64, attrs Attrs) {
+	instrument, ok := c.definition.instrument(ctx)
+	if !ok {
+		return
+	}
+	instrument.Add(ctx, incr, metric.WithAttributes(c.definition.attributes(attrs)...))
+}
+
+// UpDownCounter is an int64 counter which can be decremented, whose attributes are described by the Attrs struct
+type UpDownCounter[Attrs any] struct {
+	definition *metricDefinition[Attrs, metric.Int64UpDownCounter]
+}
+
+// NewUpDownCounter declares an int64 up-down counter. The instrument is lazily created from the global MeterProvider
+// on first use. NewUpDownCounter is meant to be called at package initialization: it panics if the Attrs struct is
+// invalid.
+func NewUpDownCounter[Attrs any](name string, opts ...MetricOpt) *UpDownCounter[Attrs] {
+	return &UpDownCounter[Attrs]{
+		definition: newMetricDefinition[Attrs](name, opts, func(meter metric.Meter, name string, o metricOptions) (metric.Int64UpDownCounter, error) {
+			return meter.Int64UpDownCounter(name, metric.WithDescription(o.description), metric.WithUnit(o.unit))
+		}),
+	}
+}
+
+// Add increments (or decrements if negative) the counter for the given attributes
+func (c *UpDownCounter[Attrs]) Add(ctx context.Context, incr int64, attrs Attrs) {
+	instrument, ok := c.definition.instrument(ctx)
+	if !ok {
+		return
+	}
+	instrument.Add(ctx, incr, metric.WithAttributes(c.definition.attributes(attrs)...))
+}
+
+// Gauge is a float64 gauge whose attributes are described by the Attrs struct
+type Gauge[Attrs any] struct {
+	definition *metricDefinition[Attrs, metric.Float64Gauge]
+}
+
+// NewGauge declares a float64 gauge. The instrument is lazily created from the global MeterProvider on first use.
+// NewGauge is meant to be called at package initialization: it panics if the Attrs struct is invalid.
+func NewGauge[Attrs any](name string, opts ...MetricOpt) *Gauge[Attrs] {
+	return &Gauge[Attrs]{
+		definition: newMetricDefinition[Attrs](name, opts, func(meter metric.Meter, name string, o metricOptions) (metric.Float64Gauge, error) {
+			return meter.Float64Gauge(name, metric.WithDescription(o.description), metric.WithUnit(o.unit))
+		}),
+	}
+}
+
+// Record sets the current value of the gauge for the given attributes
+func (g *Gauge[Attrs]) Record(ctx context.Context, value float64, attrs Attrs) {
+	instrument, ok := g.definition.instrument(ctx)
+	if !ok {
+		return
+	}
+	instrument.Record(ctx, value, metric.WithAttributes(g.definition.attributes(attrs)...))
+}
+
+// Histogram is a float64 histogram whose attributes are described by the Attrs struct
+type Histogram[Attrs any] struct {
+	definition *metricDefinition[Attrs, metric.Float64Histogram]
+}
+
+// NewHistogram declares a float64 histogram. The instrument is lazily created from the global MeterProvider on first
+// use. NewHistogram is meant to be called at package initialization: it panics if the Attrs struct is invalid.
+func NewHistogram[Attrs any](name string, opts ...MetricOpt) *Histogram[Attrs] {
+	return &Histogram[Attrs]{
+		definition: newMetricDefinition[Attrs](name, opts, func(meter metric.Meter, name string, o metricOptions) (metric.Float64Histogram, error) {
+			histogramOpts := []metric.Float64HistogramOption{metric.WithDescription(o.description), metric.WithUnit(o.unit)}
+			if len(o.buckets) > 0 {
+				histogramOpts = append(histogramOpts, metric.WithExplicitBucketBoundaries(o.buckets...))
+			}
+			return meter.Float64Histogram(name, histogramOpts...)
+		}),
+	}
+}
+
+// Record adds a value to the histogram for the given attributes
+func (h *Histogram[Attrs]) Record(ctx context.Context, value float64, attrs Attrs) {
+	instrument, ok := h.definition.instrument(ctx)
+	if !ok {
+		return
+	}
+	instrument.Record(ctx, value, metric.WithAttributes(h.definition.attributes(attrs)...))
+}
+
+// metricDefinition holds what is common to all the metric definitions: the attributes extraction and the lazy
+// creation of the instrument.
+type metricDefinition[Attrs any, Instrument any] struct {
+	name    string
+	options metricOptions
+	fields  []attributeField
+	create  func(metric.Meter, string, metricOptions) (Instrument, error)
+
+	// resolved is the instrument created from the global MeterProvider. It is loaded without lock when recording, and
+	// the lock is only taken to create the instrument again if the global MeterProvider changes, for instance when a test
+	// sets an in-memory MeterProvider.
+	resolved atomic.Pointer[resolvedInstrument[Instrument]]
+	lock     sync.Mutex
+}
+
+// resolvedInstrument is an instrument and the MeterProvider which created it
+type resolvedInstrument[Instrument any] struct {
+	meterProvider metric.MeterProvider
+	instrument    Instrument
+}
+
+func newMetricDefinition[Attrs any, Instrument any](
+	name string, opts []MetricOpt, create func(metric.Meter, string, metricOptions) (Instrument, error),
+) *metricDefinition[Attrs, Instrument] {
+	attrsType := reflect.TypeFor[Attrs]()
+	fields, err := attributeFields(attrsType)
+	if err != nil {
+		panic(fmt.Sprintf("invalid attributes for metric %s: %v", name, err))
+	}
+
+	options := metricOptions{meterName: attrsType.PkgPath()}
+	if options.meterName == "" {
+		options.meterName = instrumentationName
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &metricDefinition[Attrs, Instrument]{
+		name:    name,
+		options: options,
+		fields:  fields,
+		create:  create,
+	}
+}
+
+// instrument returns the instrument created from the current global MeterProvider. The boolean is false if the
+// instrument cannot be created, in which case the error is logged through the OpenTelemetry error handler.
+func (d *metricDefinition[Attrs, Instrument]) instrument(_ context.Context) (Instrument, bool) {
+	meterProvider := otelsdk.GetMeterProvider()
+
+	resolved := d.resolved.Load()
+	if resolved != nil && resolved.meterProvider == meterProvider {
+		return resolved.instrument, true
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	resolved = d.resolved.Load()
+	if resolved != nil && resolved.meterProvider == meterProvider {
+		return resolved.instrument, true
+	}
+
+	instrument, err := d.create(meterProvider.Meter(d.options.meterName), d.name, d.options)
+	if err != nil {
+		otelsdk.Handle(fmt.Errorf("create instrument %s: %w", d.name, err))
+		var zero Instrument
+		return zero, false
+	}
+	d.resolved.Store(&resolvedInstrument[Instrument]{meterProvider: meterProvider, instrument: instrument})
+	return instrument, true
+}
+
+// attributes returns the attributes of the struct. The fields of a nil embedded struct pointer are omitted.
+func (d *metricDefinition[Attrs, Instrument]) attributes(attrs Attrs) []attribute.KeyValue {
+	value := reflect.ValueOf(attrs)
+	keyValues := make([]attribute.KeyValue, 0, len(d.fields))
+	for _, field := range d.fields {
+		fieldValue, err := value.FieldByIndexErr(field.index)
+		if err != nil {
+			continue
+		}
+		if field.omitEmpty && fieldValue.IsZero() {
+			continue
+		}
+		keyValues = append(keyValues, field.keyValue(fieldValue))
+	}
+	return keyValues
+}
+
+// attributeField is a field of the attributes struct of a metric definition
+type attributeField struct {
+	index     []int
+	key       attribute.Key
+	omitEmpty bool
+	keyValue  func(reflect.Value) attribute.KeyValue
+}
+
+var stringerType = reflect.TypeFor[fmt.Stringer]()
+
+// attributeFields validates the attributes struct of a metric definition and returns its attribute fields
+func attributeFields(attrsType reflect.Type) ([]attributeField, error) {
+	if attrsType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct", attrsType)
+	}
+
+	var fields []attributeField
+	seen := map[attribute.Key]string{}
+	for _, structField := range reflect.VisibleFields(attrsType) {
+		tag, found := structField.Tag.Lookup(attributeTagName)
+		if !found || tag == "-" {
+			continue
+		}
+		if !structField.IsExported() {
+			return nil, fmt.Errorf("field %s is not exported", structField.Name)
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if !attributeNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("field %s has an invalid attribute name '%s'", structField.Name, name)
+		}
+		key := attribute.Key(name)
+		if otherField, ok := seen[key]; ok {
+			return nil, fmt.Errorf("fields %s and %s have the same attribute name '%s'", otherField, structField.Name, name)
+		}
+		seen[key] = structField.Name
+
+		omitEmpty := false
+		for option := range strings.SplitSeq(options, ",") {
+			switch option {
+			case "":
+			case "omitempty":
+				omitEmpty = true
+			default:
+				return nil, fmt.Errorf("field %s has an unknown tag option '%s'", structField.Name, option)
+			}
+		}
+
+		keyValue, err := attributeKeyValueFunc(key, structField.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", structField.Name, err)
+		}
+
+		fields = append(fields, attributeField{
+			index:     structField.Index,
+			key:       key,
+			omitEmpty: omitEmpty,
+			keyValue:  keyValue,
+		})
+	}
+
+	if len(fields) == 0 && attrsType.NumField() > 0 {
+		return nil, fmt.Errorf("%s has no field with an `%s` tag", attrsType, attributeTagName)
+	}
+	return fields, nil
+}
+
+// attributeKeyValueFunc returns the function converting a field value of the given type to an attribute
+func attributeKeyValueFunc(key attribute.Key, fieldType reflect.Type) (func(reflect.Value) attribute.KeyValue, error) {
+	if fieldType.Implements(stringerType) {
+		return func(v reflect.Value) attribute.KeyValue {
+			if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+				return key.String("")
+			}
+			//nolint:errcheck // The type implements fmt.Stringer.
+			return key.String(v.Interface().(fmt.Stringer).String())
+		}, nil
+	}
+
+	switch fieldType.Kind() {
+	case reflect.String:
+		return func(v reflect.Value) attribute.KeyValue { return key.String(v.String()) }, nil
+	case reflect.Bool:
+		return func(v reflect.Value) attribute.KeyValue { return key.Bool(v.Bool()) }, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(v reflect.Value) attribute.KeyValue { return key.Int64(v.Int()) }, nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return func(v reflect.Value) attribute.KeyValue { return key.Int64(int64(v.Uint())) }, nil
+	case reflect.Uint, reflect.Uint64:
+		// The attributes have no unsigned type, the values overflowing an int64 are exported as strings
+		return func(v reflect.Value) attribute.KeyValue {
+			if v.Uint() > math.MaxInt64 {
+				return key.String(strconv.FormatUint(v.Uint(), 10))
+			}
+			return key.Int64(int64(v.Uint()))
+		}, nil
+	case reflect.Float32, reflect.Float64:
+		return func(v reflect.Value) attribute.KeyValue { return key.Float64(v.Float()) }, nil
+	case reflect.Slice:
+		if fieldType.Elem().Kind() == reflect.String {
+			return func(v reflect.Value) attribute.KeyValue {
+				values := make([]string, v.Len())
+				for i := range values {
+					values[i] = v.Index(i).String()
+				}
+				return key.StringSlice(values)
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported attribute type %s", fieldType)
+}
@@ -0,0 +1,186 @@
+package otel
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/Scalingo/go-utils/otel/oteltest"
+)
+
+type testDeploymentAttributes struct {
+	AppID    string `otel:"scalingo.app.id"`
+	Status   string `otel:"deployment.status,omitempty"`
+	Attempt  int    `otel:"deployment.attempt"`
+	Internal string
+}
+
+func TestNewCounter(t *testing.T) {
+	counter := NewCounter[testDeploymentAttributes]("deployment.count", WithMetricDescription("Number of deployments"))
+
+	t.Run("it records the data points with the struct attributes", func(t *testing.T) {
+		reader := oteltest.InitMetricReader(t)
+
+		counter.Add(t.Context(), 1, testDeploymentAttributes{AppID: "app-1", Status: "success", Attempt: 1, Internal: "ignored"})
+		counter.Add(t.Context(), 2, testDeploymentAttributes{AppID: "app-1", Status: "success", Attempt: 1})
+		counter.Add(t.Context(), 1, testDeploymentAttributes{AppID: "app-2", Attempt: 2})
+
+		reader.AssertCounter("deployment.count", []attribute.KeyValue{
+			attribute.String("scalingo.app.id", "app-1"),
+			attribute.String("deployment.status", "success"),
+			attribute.Int("deployment.attempt", 1),
+		}, 3)
+		reader.AssertCounter("deployment.count", []attribute.KeyValue{
+			attribute.String("scalingo.app.id", "app-2"),
+			attribute.Int("deployment.attempt", 2),
+		}, 1)
+	})
+
+	t.Run("it creates the instrument again when the global MeterProvider changes", func(t *testing.T) {
+		reader := oteltest.InitMetricReader(t)
+
+		counter.Add(t.Context(), 5, testDeploymentAttributes{AppID: "app-1"})
+
+		reader.AssertCounter("deployment.count", []attribute.KeyValue{
+			attribute.String("scalingo.app.id", "app-1"),
+			attribute.Int("deployment.attempt", 0),
+		}, 5)
+	})
+}
+
+func TestNewHistogram(t *testing.T) {
+	reader := oteltest.InitMetricReader(t)
+	histogram := NewHistogram[testDeploymentAttributes]("deployment.duration", WithMetricUnit("s"), WithMetricBuckets(1, 10, 60))
+
+	histogram.Record(t.Context(), 2.5, testDeploymentAttributes{AppID: "app-1"})
+	histogram.Record(t.Context(), 30, testDeploymentAttributes{AppID: "app-1"})
+
+	attrs := []attribute.KeyValue{attribute.String("scalingo.app.id", "app-1"), attribute.Int("deployment.attempt", 0)}
+	reader.AssertHistogramCount("deployment.duration", attrs, 2)
+	assert.InDelta(t, 32.5, reader.HistogramSum("deployment.duration", attrs...), 1e-9)
+}
+
+func TestNewGauge(t *testing.T) {
+	reader := oteltest.InitMetricReader(t)
+	gauge := NewGauge[testDeploymentAttributes]("deployment.queue.size")
+	upDownCounter := NewUpDownCounter[testDeploymentAttributes]("deployment.running")
+
+	gauge.Record(t.Context(), 3, testDeploymentAttributes{AppID: "app-1"})
+	gauge.Record(t.Context(), 1, testDeploymentAttributes{AppID: "app-1"})
+	upDownCounter.Add(t.Context(), 2, testDeploymentAttributes{AppID: "app-1"})
+	upDownCounter.Add(t.Context(), -1, testDeploymentAttributes{AppID: "app-1"})
+
+	attrs := []attribute.KeyValue{attribute.String("scalingo.app.id", "app-1"), attribute.Int("deployment.attempt", 0)}
+	reader.AssertGauge("deployment.queue.size", attrs, 1)
+	reader.AssertCounter("deployment.running", attrs, 1)
+}
+
+func TestNewCounter_StringerAttributes(t *testing.T) {
+	type attributes struct {
+		Region fmt.Stringer `otel:"region"`
+	}
+	reader := oteltest.InitMetricReader(t)
+	counter := NewCounter[attributes]("test.count")
+
+	assert.NotPanics(t, func() {
+		counter.Add(t.Context(), 1, attributes{})
+	})
+	counter.Add(t.Context(), 2, attributes{Region: testRegion("osc-fr1")})
+
+	reader.AssertCounter("test.count", []attribute.KeyValue{attribute.String("region", "")}, 1)
+	reader.AssertCounter("test.count", []attribute.KeyValue{attribute.String("region", "osc-fr1")}, 2)
+}
+
+func TestNewCounter_UnsignedAttributes(t *testing.T) {
+	type attributes struct {
+		Size uint64 `otel:"size"`
+	}
+	reader := oteltest.InitMetricReader(t)
+	counter := NewCounter[attributes]("test.count")
+
+	counter.Add(t.Context(), 1, attributes{Size: 42})
+	counter.Add(t.Context(), 2, attributes{Size: math.MaxUint64})
+
+	reader.AssertCounter("test.count", []attribute.KeyValue{attribute.Int64("size", 42)}, 1)
+	reader.AssertCounter("test.count", []attribute.KeyValue{attribute.String("size", "18446744073709551615")}, 2)
+}
+
+func TestNewCounter_EmbeddedPointerAttributes(t *testing.T) {
+	type appAttributes struct {
+		AppID string `otel:"scalingo.app.id"`
+	}
+	type attributes struct {
+		*appAttributes
+		Region string `otel:"region"`
+	}
+	reader := oteltest.InitMetricReader(t)
+	counter := NewCounter[attributes]("test.count")
+
+	assert.NotPanics(t, func() {
+		counter.Add(t.Context(), 1, attributes{Region: "osc-fr1"})
+	})
+	counter.Add(t.Context(), 2, attributes{appAttributes: &appAttributes{AppID: "app-1"}, Region: "osc-fr1"})
+
+	reader.AssertCounter("test.count", []attribute.KeyValue{attribute.String("region", "osc-fr1")}, 1)
+	reader.AssertCounter("test.count", []attribute.KeyValue{attribute.String("scalingo.app.id", "app-1"), attribute.String("region", "osc-fr1")}, 2)
+}
+
+type testRegion string
+
+func (r testRegion) String() string {
+	return string(r)
+}
+
+func TestAttributeFields(t *testing.T) {
+	t.Run("it panics if the attributes are not a struct", func(t *testing.T) {
+		assert.PanicsWithValue(t, "invalid attributes for metric test.count: string is not a struct", func() {
+			NewCounter[string]("test.count")
+		})
+	})
+
+	t.Run("it panics if an attribute name is invalid", func(t *testing.T) {
+		type attributes struct {
+			AppID string `otel:"app id"`
+		}
+		assert.PanicsWithValue(t, "invalid attributes for metric test.count: field AppID has an invalid attribute name 'app id'", func() {
+			NewCounter[attributes]("test.count")
+		})
+	})
+
+	t.Run("it panics if two fields have the same attribute name", func(t *testing.T) {
+		type attributes struct {
+			AppID   string `otel:"app.id"`
+			AppName string `otel:"app.id"`
+		}
+		assert.PanicsWithValue(t, "invalid attributes for metric test.count: fields AppID and AppName have the same attribute name 'app.id'", func() {
+			NewCounter[attributes]("test.count")
+		})
+	})
+
+	t.Run("it panics if a tag option is unknown", func(t *testing.T) {
+		type attributes struct {
+			AppID string `otel:"app.id,omitempy"`
+		}
+		assert.PanicsWithValue(t, "invalid attributes for metric test.count: field AppID has an unknown tag option 'omitempy'", func() {
+			NewCounter[attributes]("test.count")
+		})
+	})
+
+	t.Run("it panics if a field type is not supported", func(t *testing.T) {
+		type attributes struct {
+			Labels map[string]string `otel:"labels"`
+		}
+		assert.PanicsWithValue(t, "invalid attributes for metric test.count: field Labels: unsupported attribute type map[string]string", func() {
+			NewCounter[attributes]("test.count")
+		})
+	})
+
+	t.Run("it accepts an empty struct", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			NewCounter[struct{}]("test.count")
+		})
+	})
+}
@@ -14,8 +14,11 @@ import (
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -29,7 +32,8 @@ type Config struct {
 	HostName          string `default:"" split_words:"true"`
 	Debug             bool   `default:"false"`
 	DebugPrettyPrint  bool   `default:"true" split_words:"true"`
-	ExporterType      string `default:"grpc" split_words:"true"`
+	ExporterType      string `default:"grpc" split_words:"true"`       // One of "grpc", "http", "stdout", "file" or "prometheus"
+	ExporterFilePath  string `default:"otel.jsonl" split_words:"true"` // Path of the JSON lines file written by the "file" exporter
 
 	// OpenTelemetry official env vars
 	// https://opentelemetry.io/docs/specs/otel/configuration/sdk-environment-variables/#general-sdk-configuration
@@ -39,16 +43,34 @@ type Config struct {
 	// https://opentelemetry.io/docs/languages/sdk-configuration/otlp-exporter/#otel_exporter_otlp_endpoint
 	// https://opentelemetry.io/docs/specs/otel/protocol/exporter/#configuration-options
 	ExporterOtlpEndpoint          string `default:"" split_words:"true"`
+	ExporterOtlpProtocol          string `default:"" split_words:"true"` // "grpc" or "http/protobuf", takes precedence over the OTLP ExporterType
 	ExporterOtlpCertificate       string `default:"" split_words:"true"` // CA Certificate
 	ExporterOtlpClientKey         string `default:"" split_words:"true"`
 	ExporterOtlpClientCertificate string `default:"" split_words:"true"`
 
+	// https://opentelemetry.io/docs/specs/otel/configuration/sdk-environment-variables/#prometheus-exporter
+	ExporterPrometheusHost string `default:"localhost" split_words:"true"`
+	ExporterPrometheusPort int    `default:"0" split_words:"true"` // If set, the "prometheus" exporter serves /metrics on its own HTTP server
+
 	// https://opentelemetry.io/docs/specs/otel/configuration/sdk-environment-variables/#periodic-exporting-metricreader
 	MetricExportInterval time.Duration `default:"10s" split_words:"true"`
+	// https://opentelemetry.io/docs/specs/otel/configuration/sdk-environment-variables/#exporter-selection
+	TracesExporter string `default:"otlp" split_words:"true"` // Set to "none" to disable the export of spans
+	// https://opentelemetry.io/docs/specs/otel/configuration/sdk-environment-variables/#general-sdk-configuration
+	TracesSampler    string `default:"parentbased_always_on" split_words:"true"`
+	TracesSamplerArg string `default:"" split_words:"true"` // Sampling ratio for the traceidratio samplers
+	// https://opentelemetry.io/docs/specs/otel/configuration/sdk-environment-variables/#exporter-selection
+	LogsExporter string `default:"otlp" split_words:"true"` // Set to "none" to disable the export of logs
 }
 
+// instrumentationName is the name of the meter used by this package to record its own metrics
+const instrumentationName = "github.com/Scalingo/go-utils/otel"
+
 type initDefaultOptions struct {
 	defaultAttributes []attribute.KeyValue
+	sanitizing        sanitizingOptions
+	runtimeMetrics    bool
+	processMetrics    bool
 }
 
 type InitOpt func(defaultAptions *initDefaultOptions)
@@ -113,20 +135,14 @@ func Init(ctx context.Context, opts ...InitOpt) func() error {
 		opt(defaultOptions)
 	}
 
-	metricsExporter, err := newMetricsExporter(ctx, cfg)
+	exporterType, err := selectExporterType(ctx, cfg)
 	if err != nil {
-		log.WithError(err).Error("OpenTelemetry SDK metrics exporter error")
-
+		log.WithError(err).Error("OpenTelemetry SDK exporter selection error")
 		return func() error {
 			return nil
 		}
 	}
 
-	metricsReader := sdkmetric.NewPeriodicReader(
-		metricsExporter,
-		sdkmetric.WithInterval(cfg.MetricExportInterval),
-	)
-
 	res, err := resource.Merge(
 		resource.Default(),
 		resource.NewSchemaless(
@@ -140,27 +156,129 @@ func Init(ctx context.Context, opts ...InitOpt) func() error {
 		}
 	}
 
+	output, err := newExporterOutput(ctx, cfg, exporterType)
+	if err != nil {
+		log.WithError(err).Error("OpenTelemetry SDK exporter output error")
+		return func() error {
+			return nil
+		}
+	}
+
+	metricsReader, err := newMetricsReader(ctx, cfg, res, exporterType, output, defaultOptions.sanitizing)
+	if err != nil {
+		log.WithError(err).Error("OpenTelemetry SDK metrics exporter error")
+		logShutdownError(ctx, shutdownProviders(ctx, nil, nil, nil, output))
+
+		return func() error {
+			return nil
+		}
+	}
+
 	// Initialize MeterProvider
-	meterProvider := sdkmetric.NewMeterProvider(
+	meterProviderOptions := []sdkmetric.Option{
 		sdkmetric.WithReader(metricsReader),
 		sdkmetric.WithResource(res),
-	)
+	}
+	meterProvider := sdkmetric.NewMeterProvider(meterProviderOptions...)
+
+	// Initialize TracerProvider
+	tracerProvider, err := newTracerProvider(ctx, cfg, res, exporterType, output)
+	if err != nil {
+		log.WithError(err).Error("OpenTelemetry SDK tracer provider error")
+		// The metrics reader may serve the metrics on its own port, and the output may be an open file
+		logShutdownError(ctx, shutdownProviders(ctx, nil, meterProvider, nil, output))
+
+		return func() error {
+			return nil
+		}
+	}
+
+	// Initialize LoggerProvider
+	loggerProvider, err := newLoggerProvider(ctx, cfg, res, exporterType, output)
+	if err != nil {
+		log.WithError(err).Error("OpenTelemetry SDK logger provider error")
+		logShutdownError(ctx, shutdownProviders(ctx, tracerProvider, meterProvider, nil, output))
+
+		return func() error {
+			return nil
+		}
+	}
 
 	// Set the MeterProvider in the OTEL SDK global in order to access it globally
 	otelsdk.SetMeterProvider(meterProvider)
+	if tracerProvider != nil {
+		otelsdk.SetTracerProvider(tracerProvider)
+	}
+	if loggerProvider != nil {
+		global.SetLoggerProvider(loggerProvider)
+	}
+	otelsdk.SetTextMapPropagator(newTextMapPropagator())
+
+	// Failing to collect the runtime and process metrics is not fatal for the application
+	if defaultOptions.runtimeMetrics {
+		err = startRuntimeMetrics(ctx, meterProvider)
+		if err != nil {
+			log.WithError(err).Error("OpenTelemetry SDK runtime metrics error")
+		}
+	}
+	if defaultOptions.processMetrics {
+		err = startProcessMetrics(ctx, meterProvider)
+		if err != nil {
+			log.WithError(err).Error("OpenTelemetry SDK process metrics error")
+		}
+	}
 
 	log.Info("OpenTelemetry SDK is properly initialized")
 
 	return func() error {
-		if meterProvider != nil {
-			log.Info("OpenTelemetry SDK shutdown")
-			err := meterProvider.Shutdown(ctx)
-			if errors.Is(err, context.Canceled) {
-				return nil
-			}
-			return errors.Wrap(ctx, err, "shutdown OpenTelemetry SDK")
+		log.Info("OpenTelemetry SDK shutdown")
+
+		return shutdownProviders(ctx, tracerProvider, meterProvider, loggerProvider, output)
+	}
+}
+
+// shutdownProviders shuts down all the providers which are not nil and closes the exporter output. The errors are
+// joined so that a failing provider does not prevent the others from flushing their data.
+func shutdownProviders(
+	ctx context.Context, tracerProvider *sdktrace.TracerProvider, meterProvider *sdkmetric.MeterProvider,
+	loggerProvider *sdklog.LoggerProvider, output *exporterOutput,
+) error {
+	var errs []error
+
+	// Spans are flushed first so that the metrics recorded while ending them are exported as well
+	if tracerProvider != nil {
+		err := tracerProvider.Shutdown(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			errs = append(errs, errors.Wrap(ctx, err, "shutdown OpenTelemetry SDK tracer provider"))
 		}
-		return nil
+	}
+
+	if meterProvider != nil {
+		err := meterProvider.Shutdown(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			errs = append(errs, errors.Wrap(ctx, err, "shutdown OpenTelemetry SDK"))
+		}
+	}
+
+	// Log records are flushed last so that the logs of the other providers shutdown are exported as well
+	if loggerProvider != nil {
+		err := loggerProvider.Shutdown(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			errs = append(errs, errors.Wrap(ctx, err, "shutdown OpenTelemetry SDK logger provider"))
+		}
+	}
+
+	err := output.Close()
+	if err != nil {
+		errs = append(errs, errors.Wrap(ctx, err, "close OpenTelemetry SDK exporter output"))
+	}
+
+	return errors.Join(errs...)
+}
+
+func logShutdownError(ctx context.Context, err error) {
+	if err != nil {
+		logger.Get(ctx).WithError(err).Error("OpenTelemetry SDK shutdown error after an initialization failure")
 	}
 }
 
@@ -174,40 +292,70 @@ func initConfiguration(ctx context.Context) (*Config, error) {
 	if cfg.ServiceName == "" {
 		return nil, errors.New(ctx, "service name is required")
 	}
-	if cfg.ExporterOtlpEndpoint == "" {
+	// The stdout and file exporters do not need any endpoint
+	exporterType, err := selectExporterType(ctx, &cfg)
+	if err != nil {
+		return nil, errors.Wrap(ctx, err, "select exporter")
+	}
+	if isOTLPExporterType(exporterType) && cfg.ExporterOtlpEndpoint == "" {
 		return nil, errors.New(ctx, "exporter OTLP endpoint is required")
 	}
 	return &cfg, nil
 }
 
-func newMetricsExporter(ctx context.Context, cfg *Config) (sdkmetric.Exporter, error) {
-	if cfg.Debug {
-		if cfg.DebugPrettyPrint {
-			return stdoutmetric.New(stdoutmetric.WithPrettyPrint())
+// newMetricsReader returns a pull based reader for the prometheus exporter type, and a reader periodically pushing the
+// metrics to the exporter otherwise
+func newMetricsReader(ctx context.Context, cfg *Config, res *resource.Resource, exporterType string, output *exporterOutput, sanitizing sanitizingOptions) (sdkmetric.Reader, error) {
+	if exporterType == ExporterTypePrometheus {
+		reader, err := newPrometheusReader(ctx, cfg, res, sanitizing)
+		if err != nil {
+			return nil, errors.Wrap(ctx, err, "create Prometheus reader")
 		}
-		return stdoutmetric.New()
+		return reader, nil
 	}
 
-	environment := os.Getenv("GO_ENV")
-
-	// Enforce TLS for production and staging environments.
-	// In development and test environments, TLS is not enforced.
-	var enforceTLSByDefault = true
-	if environment == "development" || environment == "test" {
-		enforceTLSByDefault = false
+	metricsExporter, err := newMetricsExporter(ctx, cfg, exporterType, output, sanitizing)
+	if err != nil {
+		return nil, errors.Wrap(ctx, err, "create metrics exporter")
 	}
 
+	return sdkmetric.NewPeriodicReader(
+		metricsExporter,
+		sdkmetric.WithInterval(cfg.MetricExportInterval),
+	), nil
+}
+
+func newMetricsExporter(ctx context.Context, cfg *Config, exporterType string, output *exporterOutput, sanitizing sanitizingOptions) (sdkmetric.Exporter, error) {
+	enforceTLSByDefault := isTLSEnforced()
+
 	var tlsConfig *tls.Config
 	var err error
-	if enforceTLSByDefault {
+	if enforceTLSByDefault && isOTLPExporterType(exporterType) {
 		tlsConfig, err = setTLSConfig(ctx, cfg)
 		if err != nil {
 			return nil, errors.Wrap(ctx, err, "set TLS configuration")
 		}
 	}
 
-	switch cfg.ExporterType {
-	case "http":
+	switch exporterType {
+	case ExporterTypeStdout:
+		opts := []stdoutmetric.Option{stdoutmetric.WithWriter(output)}
+		if cfg.DebugPrettyPrint {
+			opts = append(opts, stdoutmetric.WithPrettyPrint())
+		}
+		exporter, err := stdoutmetric.New(opts...)
+		if err != nil {
+			return nil, errors.Wrap(ctx, err, "create stdout exporter")
+		}
+		return newSanitizingExporter(ctx, exporter, sanitizing), nil
+	case ExporterTypeFile:
+		// Without pretty print, each export is written as a single JSON line
+		exporter, err := stdoutmetric.New(stdoutmetric.WithWriter(output))
+		if err != nil {
+			return nil, errors.Wrap(ctx, err, "create file exporter")
+		}
+		return newSanitizingExporter(ctx, exporter, sanitizing), nil
+	case ExporterTypeHTTP:
 		if enforceTLSByDefault {
 			exporter, err := otlpmetrichttp.New(
 				ctx, otlpmetrichttp.WithTLSClientConfig(tlsConfig),
@@ -215,15 +363,15 @@ func newMetricsExporter(ctx context.Context, cfg *Config) (sdkmetric.Exporter, e
 			if err != nil {
 				return nil, errors.Wrap(ctx, err, "create OTLP HTTPs exporter")
 			}
-			return newSanitizingExporter(ctx, exporter), nil
+			return newSanitizingExporter(ctx, exporter, sanitizing), nil
 		}
 
 		exporter, err := otlpmetrichttp.New(ctx)
 		if err != nil {
 			return nil, errors.Wrap(ctx, err, "create OTLP HTTP exporter")
 		}
-		return newSanitizingExporter(ctx, exporter), nil
-	case "grpc":
+		return newSanitizingExporter(ctx, exporter, sanitizing), nil
+	case ExporterTypeGRPC:
 		if enforceTLSByDefault {
 			creds := credentials.NewTLS(tlsConfig)
 			exporter, err := otlpmetricgrpc.New(
@@ -234,14 +382,14 @@ func newMetricsExporter(ctx context.Context, cfg *Config) (sdkmetric.Exporter, e
 			if err != nil {
 				return nil, errors.Wrap(ctx, err, "create OTLP gRPC (TLS) exporter")
 			}
-			return newSanitizingExporter(ctx, exporter), nil
+			return newSanitizingExporter(ctx, exporter, sanitizing), nil
 		}
 
 		exporter, err := otlpmetricgrpc.New(ctx)
 		if err != nil {
 			return nil, errors.Wrap(ctx, err, "create OTLP gRPC exporter")
 		}
-		return newSanitizingExporter(ctx, exporter), nil
+		return newSanitizingExporter(ctx, exporter, sanitizing), nil
 	default:
 		return nil, errors.New(ctx, "invalid exporter type")
 	}
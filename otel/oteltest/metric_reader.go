@@ -0,0 +1,220 @@
+package oteltest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otelsdk "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// MetricReader collects the data points recorded through the global MeterProvider in memory. Contrary to the mocked
+// MeterProvider, it does not require to declare an expectation for each instrument call: the tests assert on the
+// collected data points instead.
+type MetricReader struct {
+	t             testing.TB
+	reader        *sdkmetric.ManualReader
+	meterProvider *sdkmetric.MeterProvider
+}
+
+// InitMetricReader sets a MeterProvider backed by an in-memory reader as the global MeterProvider. The previous global
+// MeterProvider is restored at the end of the test.
+func InitMetricReader(t testing.TB) *MetricReader {
+	t.Helper()
+
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	initialMeterProvider := otelsdk.GetMeterProvider()
+	otelsdk.SetMeterProvider(meterProvider)
+	t.Cleanup(func() {
+		otelsdk.SetMeterProvider(initialMeterProvider)
+		// The test context is already canceled when the cleanup functions are called
+		_ = meterProvider.Shutdown(context.Background())
+	})
+
+	return &MetricReader{
+		t:             t,
+		reader:        reader,
+		meterProvider: meterProvider,
+	}
+}
+
+// MeterProvider returns the MeterProvider backed by the in-memory reader
+func (r *MetricReader) MeterProvider() *sdkmetric.MeterProvider {
+	return r.meterProvider
+}
+
+// Collect returns all the data points recorded so far
+func (r *MetricReader) Collect() metricdata.ResourceMetrics {
+	r.t.Helper()
+
+	var metrics metricdata.ResourceMetrics
+	err := r.reader.Collect(r.t.Context(), &metrics)
+	require.NoError(r.t, err)
+	return metrics
+}
+
+// Metric returns the metric with the given name. The boolean is false if no data point has been recorded for this
+// metric.
+func (r *MetricReader) Metric(name string) (metricdata.Metrics, bool) {
+	r.t.Helper()
+
+	metrics := r.Collect()
+	for _, scopeMetrics := range metrics.ScopeMetrics {
+		for _, metric := range scopeMetrics.Metrics {
+			if metric.Name == name {
+				return metric, true
+			}
+		}
+	}
+	return metricdata.Metrics{}, false
+}
+
+// CounterValue returns the value of the counter (or up-down counter) with the given name and exact attributes. The
+// boolean is false if no such data point exists.
+func (r *MetricReader) CounterValue(name string, attrs ...attribute.KeyValue) (float64, bool) {
+	r.t.Helper()
+
+	metric, ok := r.Metric(name)
+	if !ok {
+		return 0, false
+	}
+
+	switch data := metric.Data.(type) {
+	case metricdata.Sum[int64]:
+		return dataPointValue(data.DataPoints, attrs)
+	case metricdata.Sum[float64]:
+		return dataPointValue(data.DataPoints, attrs)
+	default:
+		r.t.Errorf("metric %s is not a counter but a %T", name, metric.Data)
+		return 0, false
+	}
+}
+
+// GaugeValue returns the last value of the gauge with the given name and exact attributes. The boolean is false if no
+// such data point exists.
+func (r *MetricReader) GaugeValue(name string, attrs ...attribute.KeyValue) (float64, bool) {
+	r.t.Helper()
+
+	metric, ok := r.Metric(name)
+	if !ok {
+		return 0, false
+	}
+
+	switch data := metric.Data.(type) {
+	case metricdata.Gauge[int64]:
+		return dataPointValue(data.DataPoints, attrs)
+	case metricdata.Gauge[float64]:
+		return dataPointValue(data.DataPoints, attrs)
+	default:
+		r.t.Errorf("metric %s is not a gauge but a %T", name, metric.Data)
+		return 0, false
+	}
+}
+
+// HistogramCount returns the number of values recorded by the histogram with the given name and exact attributes
+func (r *MetricReader) HistogramCount(name string, attrs ...attribute.KeyValue) uint64 {
+	r.t.Helper()
+
+	dataPoint, ok := r.histogramDataPoint(name, attrs)
+	if !ok {
+		return 0
+	}
+	return dataPoint.Count
+}
+
+// HistogramSum returns the sum of the values recorded by the histogram with the given name and exact attributes
+func (r *MetricReader) HistogramSum(name string, attrs ...attribute.KeyValue) float64 {
+	r.t.Helper()
+
+	dataPoint, ok := r.histogramDataPoint(name, attrs)
+	if !ok {
+		return 0
+	}
+	return dataPoint.Sum
+}
+
+// AssertCounter asserts that the counter with the given name and exact attributes has the expected value
+func (r *MetricReader) AssertCounter(name string, attrs []attribute.KeyValue, value float64) bool {
+	r.t.Helper()
+
+	actual, ok := r.CounterValue(name, attrs...)
+	if !assert.Truef(r.t, ok, "no data point for counter %s with attributes %v", name, attrs) {
+		return false
+	}
+	return assert.InDeltaf(r.t, value, actual, 1e-9, "unexpected value for counter %s with attributes %v", name, attrs)
+}
+
+// AssertGauge asserts that the gauge with the given name and exact attributes has the expected value
+func (r *MetricReader) AssertGauge(name string, attrs []attribute.KeyValue, value float64) bool {
+	r.t.Helper()
+
+	actual, ok := r.GaugeValue(name, attrs...)
+	if !assert.Truef(r.t, ok, "no data point for gauge %s with attributes %v", name, attrs) {
+		return false
+	}
+	return assert.InDeltaf(r.t, value, actual, 1e-9, "unexpected value for gauge %s with attributes %v", name, attrs)
+}
+
+// AssertHistogramCount asserts that the histogram with the given name and exact attributes recorded the expected
+// number of values
+func (r *MetricReader) AssertHistogramCount(name string, attrs []attribute.KeyValue, count uint64) bool {
+	r.t.Helper()
+
+	return assert.Equalf(r.t, count, r.HistogramCount(name, attrs...), "unexpected count for histogram %s with attributes %v", name, attrs)
+}
+
+// AssertNoMetric asserts that no data point has been recorded for the metric with the given name
+func (r *MetricReader) AssertNoMetric(name string) bool {
+	r.t.Helper()
+
+	_, ok := r.Metric(name)
+	return assert.Falsef(r.t, ok, "unexpected data points for metric %s", name)
+}
+
+// histogramDataPoint converts the histogram data point to float64 so that int64 and float64 histograms are handled
+// the same way
+func (r *MetricReader) histogramDataPoint(name string, attrs []attribute.KeyValue) (metricdata.HistogramDataPoint[float64], bool) {
+	r.t.Helper()
+
+	metric, ok := r.Metric(name)
+	if !ok {
+		return metricdata.HistogramDataPoint[float64]{}, false
+	}
+
+	set := attribute.NewSet(attrs...)
+	switch data := metric.Data.(type) {
+	case metricdata.Histogram[int64]:
+		for _, dataPoint := range data.DataPoints {
+			if dataPoint.Attributes.Equals(&set) {
+				return metricdata.HistogramDataPoint[float64]{
+					Attributes: dataPoint.Attributes, Count: dataPoint.Count, Sum: float64(dataPoint.Sum),
+				}, true
+			}
+		}
+	case metricdata.Histogram[float64]:
+		for _, dataPoint := range data.DataPoints {
+			if dataPoint.Attributes.Equals(&set) {
+				return dataPoint, true
+			}
+		}
+	default:
+		r.t.Errorf("metric %s is not a histogram but a %T", name, metric.Data)
+	}
+	return metricdata.HistogramDataPoint[float64]{}, false
+}
+
+func dataPointValue[N int64 | float64](dataPoints []metricdata.DataPoint[N], attrs []attribute.KeyValue) (float64, bool) {
+	set := attribute.NewSet(attrs...)
+	for _, dataPoint := range dataPoints {
+		if dataPoint.Attributes.Equals(&set) {
+			return float64(dataPoint.Value), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,55 @@
+package oteltest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	otelsdk "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+func TestMetricReader(t *testing.T) {
+	reader := InitMetricReader(t)
+	ctx := t.Context()
+	meter := otelsdk.Meter("test")
+	attrs := []attribute.KeyValue{attribute.String("app", "my-app")}
+
+	counter, err := meter.Int64Counter("test.counter")
+	assert.NoError(t, err)
+	counter.Add(ctx, 2, metric.WithAttributes(attrs...))
+	counter.Add(ctx, 3, metric.WithAttributes(attrs...))
+	counter.Add(ctx, 1)
+
+	gauge, err := meter.Float64Gauge("test.gauge")
+	assert.NoError(t, err)
+	gauge.Record(ctx, 1.5, metric.WithAttributes(attrs...))
+	gauge.Record(ctx, 4.5, metric.WithAttributes(attrs...))
+
+	histogram, err := meter.Int64Histogram("test.histogram")
+	assert.NoError(t, err)
+	histogram.Record(ctx, 10, metric.WithAttributes(attrs...))
+	histogram.Record(ctx, 20, metric.WithAttributes(attrs...))
+
+	reader.AssertCounter("test.counter", attrs, 5)
+	reader.AssertCounter("test.counter", nil, 1)
+	reader.AssertGauge("test.gauge", attrs, 4.5)
+	reader.AssertHistogramCount("test.histogram", attrs, 2)
+	assert.InDelta(t, 30, reader.HistogramSum("test.histogram", attrs...), 0.001)
+	assert.Zero(t, reader.HistogramCount("test.histogram"))
+	reader.AssertNoMetric("test.unknown")
+
+	_, ok := reader.CounterValue("test.counter", attribute.String("app", "other"))
+	assert.False(t, ok)
+}
+
+func TestInitMetricReader_RestoresMeterProvider(t *testing.T) {
+	initialMeterProvider := otelsdk.GetMeterProvider()
+
+	t.Run("with reader", func(t *testing.T) {
+		reader := InitMetricReader(t)
+		assert.Same(t, reader.MeterProvider(), otelsdk.GetMeterProvider())
+	})
+
+	assert.Same(t, initialMeterProvider, otelsdk.GetMeterProvider())
+}
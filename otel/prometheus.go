@@ -0,0 +1,151 @@
+package otel
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"github.com/Scalingo/go-utils/errors/v3"
+	"github.com/Scalingo/go-utils/logger"
+)
+
+// prometheusHandler is the handler serving the metrics collected by the prometheus exporter. It is nil until Init is
+// called with the prometheus exporter type.
+var prometheusHandler atomic.Pointer[http.Handler]
+
+// MetricsHandler returns the handler serving the metrics in the Prometheus text format when the exporter type is
+// "prometheus". It can be mounted on the "/metrics" path of any HTTP server, for instance one started with a
+// graceful.Service. It responds with a 503 status code as long as the prometheus exporter is not initialized.
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler := prometheusHandler.Load()
+		if handler == nil {
+			http.Error(w, "Prometheus exporter is not initialized", http.StatusServiceUnavailable)
+			return
+		}
+		(*handler).ServeHTTP(w, r)
+	})
+}
+
+// prometheusReader is the reader of the metrics scraped by Prometheus. The metrics it collects are sanitized like the
+// pushed metrics, then served by the OpenTelemetry Prometheus exporter when Prometheus scrapes the metrics handler. If a
+// port is configured, it also serves the metrics handler on its own HTTP server, stopped on shutdown.
+type prometheusReader struct {
+	*sdkmetric.ManualReader
+
+	// exporterProvider only registers the Prometheus exporter, whose metrics are produced by the reader
+	exporterProvider *sdkmetric.MeterProvider
+	server           *http.Server
+}
+
+func newPrometheusReader(ctx context.Context, cfg *Config, res *resource.Resource, sanitizing sanitizingOptions) (*prometheusReader, error) {
+	log := logger.Get(ctx)
+
+	sanitizer := newSanitizingExporter(ctx, nil, sanitizing)
+	reader := &prometheusReader{
+		ManualReader: sdkmetric.NewManualReader(),
+	}
+
+	registry := prometheus.NewRegistry()
+	exporter, err := otelprometheus.New(
+		otelprometheus.WithRegisterer(registry),
+		otelprometheus.WithProducer(sanitizingProducer{reader: reader.ManualReader, sanitizer: sanitizer}),
+	)
+	if err != nil {
+		return nil, errors.Wrap(ctx, err, "create Prometheus exporter")
+	}
+
+	// Attributes whose names only differ by invalid characters end up with the same label names, and the series colliding
+	// with another one are skipped instead of failing the whole scrape
+	var handler http.Handler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		ErrorLog:      log,
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+
+	var listener net.Listener
+	if cfg.ExporterPrometheusPort != 0 {
+		addr := net.JoinHostPort(cfg.ExporterPrometheusHost, strconv.Itoa(cfg.ExporterPrometheusPort))
+		listener, err = net.Listen("tcp", addr)
+		if err != nil {
+			return nil, errors.Wrapf(ctx, err, "listen on '%s'", addr)
+		}
+	}
+
+	// The resource of the exporter provider is the one exported in the target_info metric
+	metrics := &metricdata.ResourceMetrics{Resource: res}
+	sanitizer.sanitizeResourceMetrics(ctx, metrics)
+	reader.exporterProvider = sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(exporter),
+		sdkmetric.WithResource(metrics.Resource),
+	)
+	prometheusHandler.Store(&handler)
+
+	if listener == nil {
+		return reader, nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", handler)
+	reader.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		err := reader.server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.WithError(err).Error("Prometheus metrics server error")
+		}
+	}()
+	log.WithField("address", listener.Addr().String()).Info("Prometheus metrics server started")
+
+	return reader, nil
+}
+
+func (r *prometheusReader) Shutdown(ctx context.Context) error {
+	prometheusHandler.Store(nil)
+
+	var errs []error
+	if r.server != nil {
+		err := r.server.Shutdown(ctx)
+		if err != nil {
+			errs = append(errs, errors.Wrap(ctx, err, "shutdown Prometheus metrics server"))
+		}
+	}
+	err := r.exporterProvider.Shutdown(ctx)
+	if err != nil {
+		errs = append(errs, errors.Wrap(ctx, err, "shutdown Prometheus exporter"))
+	}
+	err = r.ManualReader.Shutdown(ctx)
+	if err != nil {
+		errs = append(errs, errors.Wrap(ctx, err, "shutdown Prometheus reader"))
+	}
+	return errors.Join(errs...)
+}
+
+// sanitizingProducer produces the sanitized metrics collected by the reader. The sanitizer applies the same
+// attributes filtering and cardinality limits as to the pushed metrics.
+type sanitizingProducer struct {
+	reader    *sdkmetric.ManualReader
+	sanitizer sanitizingExporter
+}
+
+func (p sanitizingProducer) Produce(ctx context.Context) ([]metricdata.ScopeMetrics, error) {
+	var metrics metricdata.ResourceMetrics
+	err := p.reader.Collect(ctx, &metrics)
+	if err != nil {
+		return nil, errors.Wrap(ctx, err, "collect metrics")
+	}
+
+	p.sanitizer.sanitizeResourceMetrics(ctx, &metrics)
+	return metrics.ScopeMetrics, nil
+}
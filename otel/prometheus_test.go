@@ -0,0 +1,149 @@
+package otel
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otelsdk "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+func TestInit_PrometheusExporter(t *testing.T) {
+	t.Setenv("GO_ENV", "production")
+	t.Setenv("OTEL_SERVICE_NAME", "test")
+	t.Setenv("OTEL_EXPORTER_TYPE", "prometheus")
+
+	// No OTLP endpoint nor TLS configuration is required with the prometheus exporter
+	shutdown := Init(t.Context(), WithMetricAttributesDenyList("test.counter", "user_id"))
+
+	meter := otelsdk.Meter("test")
+	counter, err := meter.Int64Counter("test.counter")
+	require.NoError(t, err)
+	counter.Add(t.Context(), 1, metric.WithAttributes(attribute.String("app", "my-app"), attribute.String("user_id", "1")))
+	counter.Add(t.Context(), 2, metric.WithAttributes(attribute.String("app", "my-app"), attribute.String("user_id", "2")))
+	counter.Add(t.Context(), 4, metric.WithAttributes(attribute.String("region", "osc-fr1")))
+	histogram, err := meter.Float64Histogram("test.duration", metric.WithUnit("s"), metric.WithExplicitBucketBoundaries(1, 5))
+	require.NoError(t, err)
+	histogram.Record(t.Context(), 0.5)
+	histogram.Record(t.Context(), 3)
+
+	// The attributes "app.name" and "app_name" end up with the same label name
+	collidingCounter, err := meter.Int64Counter("test.colliding")
+	require.NoError(t, err)
+	collidingCounter.Add(t.Context(), 1, metric.WithAttributes(attribute.String("app.name", "a"), attribute.String("app_name", "b")))
+	collidingCounter.Add(t.Context(), 1, metric.WithAttributes(attribute.String("app.name", "b"), attribute.String("app_name", "a")))
+
+	body := scrapeMetrics(t, MetricsHandler())
+	// The attributes dropped during a scrape are counted in the next one
+	body = scrapeMetrics(t, MetricsHandler())
+	scope := `otel_scope_name="test",otel_scope_schema_url="",otel_scope_version=""`
+	assert.Contains(t, body, `test_counter_total{app="my-app",`+scope+`} 3`)
+	assert.Contains(t, body, `test_counter_total{`+scope+`,region="osc-fr1"} 4`)
+	assert.NotContains(t, body, "user_id")
+	assert.Contains(t, body, `otel_sanitizer_dropped_attributes_total{metric_name="test.counter",`)
+	assert.Contains(t, body, `test_duration_seconds_bucket{`+scope+`,le="1"} 1`)
+	assert.Contains(t, body, `test_duration_seconds_bucket{`+scope+`,le="5"} 2`)
+	assert.Contains(t, body, `test_duration_seconds_count{`+scope+`} 2`)
+	// The colliding series is skipped without failing the scrape
+	assert.Contains(t, body, `test_colliding_total{app_name="a;b",`+scope+`} 1`)
+	assert.Contains(t, body, `target_info{`)
+	assert.Contains(t, body, `service_name="test"`)
+
+	require.NoError(t, shutdown())
+
+	res := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+}
+
+func TestInit_PrometheusExporterCardinalityLimit(t *testing.T) {
+	t.Setenv("GO_ENV", "production")
+	t.Setenv("OTEL_SERVICE_NAME", "test")
+	t.Setenv("OTEL_EXPORTER_TYPE", "prometheus")
+
+	shutdown := Init(t.Context(), WithCardinalityLimit(2))
+	t.Cleanup(func() {
+		require.NoError(t, shutdown())
+	})
+
+	counter, err := otelsdk.Meter("test").Int64Counter("test.limited")
+	require.NoError(t, err)
+	counter.Add(t.Context(), 1, metric.WithAttributes(attribute.String("app", "a")))
+	counter.Add(t.Context(), 2, metric.WithAttributes(attribute.String("app", "b")))
+	counter.Add(t.Context(), 4, metric.WithAttributes(attribute.String("app", "c")))
+
+	// The data points are collected in no particular order, any of them can be the one admitted
+	body := scrapeMetrics(t, MetricsHandler())
+	assert.Equal(t, 1, strings.Count(body, `test_limited_total{app=`))
+	assert.Contains(t, body, `test_limited_total{otel_metric_overflow="true",`)
+}
+
+func TestInit_PrometheusExporterMetricCardinalityLimit(t *testing.T) {
+	t.Setenv("GO_ENV", "production")
+	t.Setenv("OTEL_SERVICE_NAME", "test")
+	t.Setenv("OTEL_EXPORTER_TYPE", "prometheus")
+
+	shutdown := Init(t.Context(), WithMetricCardinalityLimit("test.limited", 2))
+	t.Cleanup(func() {
+		require.NoError(t, shutdown())
+	})
+
+	meter := otelsdk.Meter("test")
+	limited, err := meter.Int64Counter("test.limited")
+	require.NoError(t, err)
+	unlimited, err := meter.Int64Counter("test.unlimited")
+	require.NoError(t, err)
+	for _, app := range []string{"a", "b", "c"} {
+		limited.Add(t.Context(), 1, metric.WithAttributes(attribute.String("app", app)))
+		unlimited.Add(t.Context(), 1, metric.WithAttributes(attribute.String("app", app)))
+	}
+
+	body := scrapeMetrics(t, MetricsHandler())
+	assert.Equal(t, 1, strings.Count(body, `test_limited_total{app=`))
+	assert.Contains(t, body, `test_limited_total{otel_metric_overflow="true",`)
+	assert.Equal(t, 3, strings.Count(body, `test_unlimited_total{app=`))
+}
+
+func TestInit_PrometheusExporterOwnPort(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	t.Setenv("GO_ENV", "test")
+	t.Setenv("OTEL_SERVICE_NAME", "test")
+	t.Setenv("OTEL_EXPORTER_TYPE", "prometheus")
+	t.Setenv("OTEL_EXPORTER_PROMETHEUS_PORT", strconv.Itoa(port))
+
+	shutdown := Init(t.Context())
+
+	res, err := http.Get("http://localhost:" + strconv.Itoa(port) + "/metrics")
+	require.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, string(body), "target_info")
+
+	require.NoError(t, shutdown())
+
+	_, err = http.Get("http://localhost:" + strconv.Itoa(port) + "/metrics")
+	require.Error(t, err)
+}
+
+func scrapeMetrics(t *testing.T, handler http.Handler) string {
+	t.Helper()
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, res.Code)
+	return res.Body.String()
+}
@@ -0,0 +1,127 @@
+package otel
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/runtime"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/semconv/v1.37.0/processconv"
+
+	"github.com/Scalingo/go-utils/errors/v3"
+)
+
+// WithRuntimeMetrics enables the collection of the Go runtime metrics (memory, garbage collector, goroutines...)
+// following the semantic conventions: https://opentelemetry.io/docs/specs/semconv/runtime/go-metrics/
+func WithRuntimeMetrics() InitOpt {
+	return func(o *initDefaultOptions) {
+		o.runtimeMetrics = true
+	}
+}
+
+// WithProcessMetrics enables the collection of the process metrics (CPU time, memory, open file descriptors, uptime)
+// following the semantic conventions: https://opentelemetry.io/docs/specs/semconv/system/process-metrics/
+func WithProcessMetrics() InitOpt {
+	return func(o *initDefaultOptions) {
+		o.processMetrics = true
+	}
+}
+
+func startRuntimeMetrics(ctx context.Context, meterProvider metric.MeterProvider) error {
+	err := runtime.Start(runtime.WithMeterProvider(meterProvider))
+	if err != nil {
+		return errors.Wrap(ctx, err, "start runtime instrumentation")
+	}
+	return nil
+}
+
+// processStats are the statistics of the current process. The values which are not available on the current platform
+// are left to nil.
+type processStats struct {
+	userCPUTime   *time.Duration
+	systemCPUTime *time.Duration
+	memoryUsage   *int64
+	memoryVirtual *int64
+	openFDs       *int64
+}
+
+func startProcessMetrics(ctx context.Context, meterProvider metric.MeterProvider) error {
+	meter := meterProvider.Meter(instrumentationName)
+	startTime := time.Now()
+
+	cpuTime, err := processconv.NewCPUTime(meter)
+	if err != nil {
+		return errors.Wrap(ctx, err, "create CPU time instrument")
+	}
+	memoryUsage, err := meter.Int64ObservableUpDownCounter(
+		processconv.MemoryUsage{}.Name(),
+		metric.WithDescription(processconv.MemoryUsage{}.Description()),
+		metric.WithUnit(processconv.MemoryUsage{}.Unit()),
+	)
+	if err != nil {
+		return errors.Wrap(ctx, err, "create memory usage instrument")
+	}
+	memoryVirtual, err := meter.Int64ObservableUpDownCounter(
+		processconv.MemoryVirtual{}.Name(),
+		metric.WithDescription(processconv.MemoryVirtual{}.Description()),
+		metric.WithUnit(processconv.MemoryVirtual{}.Unit()),
+	)
+	if err != nil {
+		return errors.Wrap(ctx, err, "create virtual memory instrument")
+	}
+	openFDs, err := meter.Int64ObservableUpDownCounter(
+		processconv.OpenFileDescriptorCount{}.Name(),
+		metric.WithDescription(processconv.OpenFileDescriptorCount{}.Description()),
+		metric.WithUnit(processconv.OpenFileDescriptorCount{}.Unit()),
+	)
+	if err != nil {
+		return errors.Wrap(ctx, err, "create open file descriptors instrument")
+	}
+	uptime, err := meter.Float64ObservableGauge(
+		processconv.Uptime{}.Name(),
+		metric.WithDescription(processconv.Uptime{}.Description()),
+		metric.WithUnit(processconv.Uptime{}.Unit()),
+	)
+	if err != nil {
+		return errors.Wrap(ctx, err, "create uptime instrument")
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, observer metric.Observer) error {
+		stats := readProcessStats()
+
+		if stats.userCPUTime != nil {
+			observer.ObserveFloat64(cpuTime.Inst(), stats.userCPUTime.Seconds(), metric.WithAttributes(cpuTime.AttrCPUMode(processconv.CPUModeUser)))
+		}
+		if stats.systemCPUTime != nil {
+			observer.ObserveFloat64(cpuTime.Inst(), stats.systemCPUTime.Seconds(), metric.WithAttributes(cpuTime.AttrCPUMode(processconv.CPUModeSystem)))
+		}
+		if stats.memoryUsage != nil {
+			observer.ObserveInt64(memoryUsage, *stats.memoryUsage)
+		}
+		if stats.memoryVirtual != nil {
+			observer.ObserveInt64(memoryVirtual, *stats.memoryVirtual)
+		}
+		if stats.openFDs != nil {
+			observer.ObserveInt64(openFDs, *stats.openFDs)
+		}
+		observer.ObserveFloat64(uptime, time.Since(startTime).Seconds())
+		return nil
+	}, cpuTime.Inst(), memoryUsage, memoryVirtual, openFDs, uptime)
+	if err != nil {
+		return errors.Wrap(ctx, err, "register process metrics callback")
+	}
+	return nil
+}
+
+// countOpenFDs counts the open file descriptors of the current process listed in the given directory (/proc/self/fd
+// on Linux, /dev/fd on macOS)
+func countOpenFDs(dir string) *int64 {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	// Reading the directory opens a file descriptor which is not counted
+	count := int64(len(entries)) - 1
+	return &count
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package otel
+
+// readProcessStats only reports the uptime on non-Unix platforms
+func readProcessStats() processStats {
+	return processStats{}
+}
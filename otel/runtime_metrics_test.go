@@ -0,0 +1,59 @@
+package otel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestStartRuntimeMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	err := startRuntimeMetrics(t.Context(), meterProvider)
+	require.NoError(t, err)
+
+	names := collectMetricNames(t, reader)
+	assert.Contains(t, names, "go.goroutine.count")
+	assert.Contains(t, names, "go.memory.used")
+}
+
+func TestStartProcessMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	err := startProcessMetrics(t.Context(), meterProvider)
+	require.NoError(t, err)
+
+	names := collectMetricNames(t, reader)
+	assert.Contains(t, names, "process.cpu.time")
+	assert.Contains(t, names, "process.uptime")
+	stats := readProcessStats()
+	if stats.memoryUsage != nil {
+		assert.Contains(t, names, "process.memory.usage")
+		assert.Positive(t, *stats.memoryUsage)
+	}
+	if stats.openFDs != nil {
+		assert.Contains(t, names, "process.open_file_descriptor.count")
+		assert.Positive(t, *stats.openFDs)
+	}
+}
+
+func collectMetricNames(t *testing.T, reader *sdkmetric.ManualReader) []string {
+	t.Helper()
+
+	var metrics metricdata.ResourceMetrics
+	err := reader.Collect(t.Context(), &metrics)
+	require.NoError(t, err)
+
+	var names []string
+	for _, scopeMetrics := range metrics.ScopeMetrics {
+		for _, metric := range scopeMetrics.Metrics {
+			names = append(names, metric.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,62 @@
+//go:build unix
+
+package otel
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+func readProcessStats() processStats {
+	var stats processStats
+
+	var usage syscall.Rusage
+	err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage)
+	if err == nil {
+		userCPUTime := time.Duration(usage.Utime.Nano())
+		systemCPUTime := time.Duration(usage.Stime.Nano())
+		stats.userCPUTime = &userCPUTime
+		stats.systemCPUTime = &systemCPUTime
+	}
+
+	if runtime.GOOS == "linux" {
+		stats.memoryVirtual, stats.memoryUsage = readLinuxStatm()
+		stats.openFDs = countOpenFDs("/proc/self/fd")
+	} else {
+		stats.openFDs = countOpenFDs("/dev/fd")
+	}
+
+	return stats
+}
+
+// readLinuxStatm returns the virtual memory size and the resident set size of the current process in bytes
+// https://man7.org/linux/man-pages/man5/proc_pid_statm.5.html
+func readLinuxStatm() (*int64, *int64) {
+	content, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return nil, nil
+	}
+
+	fields := bytes.Fields(content)
+	if len(fields) < 2 {
+		return nil, nil
+	}
+	pageSize := int64(os.Getpagesize())
+
+	virtualPages, err := strconv.ParseInt(string(fields[0]), 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+	residentPages, err := strconv.ParseInt(string(fields[1]), 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+
+	virtual := virtualPages * pageSize
+	resident := residentPages * pageSize
+	return &virtual, &resident
+}
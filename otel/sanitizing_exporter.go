@@ -2,9 +2,14 @@ package otel
 
 import (
 	"context"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	otelsdk "go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -12,15 +17,108 @@ import (
 	"github.com/Scalingo/go-utils/logger"
 )
 
+const (
+	// OverflowAttributeKey is the attribute of the series in which the data points exceeding the cardinality limit of a
+	// metric are folded. It is the attribute of the overflow series of the SDK.
+	OverflowAttributeKey = attribute.Key("otel.metric.overflow")
+
+	// DroppedAttributesMetricName is the name of the counter of attributes dropped by the sanitizing exporter.
+	DroppedAttributesMetricName = "otel.sanitizer.dropped_attributes"
+)
+
+// Reasons for which an attribute is dropped, used as the "reason" attribute of the dropped attributes counter
+const (
+	droppedReasonEmpty            = "empty"
+	droppedReasonDenied           = "denied"
+	droppedReasonNotAllowed       = "not_allowed"
+	droppedReasonCardinalityLimit = "cardinality_limit"
+)
+
+// sanitizingOptions configures the attributes kept by the sanitizing exporter. The zero value keeps all the valid
+// attributes without any cardinality limit.
+type sanitizingOptions struct {
+	// cardinalityLimit is the default maximum number of attribute sets per metric, the overflow series included. 0 means
+	// no limit.
+	cardinalityLimit int
+	// metricCardinalityLimits overrides cardinalityLimit for specific metrics
+	metricCardinalityLimits map[string]int
+	// allowedAttributes lists, per metric, the only attributes which are exported
+	allowedAttributes map[string]map[attribute.Key]bool
+	// deniedAttributes lists, per metric, the attributes which are never exported
+	deniedAttributes map[string]map[attribute.Key]bool
+}
+
+func (o sanitizingOptions) cardinalityLimitFor(metricName string) int {
+	limit, ok := o.metricCardinalityLimits[metricName]
+	if ok {
+		return limit
+	}
+	return o.cardinalityLimit
+}
+
+// WithCardinalityLimit limits the number of attribute sets exported for each metric. Once the limit is reached, data
+// points with new attribute sets are folded into a single series with the "otel.metric.overflow=true" attribute. The overflow
+// series counts in the limit.
+func WithCardinalityLimit(limit int) InitOpt {
+	return func(o *initDefaultOptions) {
+		o.sanitizing.cardinalityLimit = limit
+	}
+}
+
+// WithMetricCardinalityLimit overrides the cardinality limit of the given metric. A limit of 0 disables the limit for
+// this metric.
+func WithMetricCardinalityLimit(metricName string, limit int) InitOpt {
+	return func(o *initDefaultOptions) {
+		if o.sanitizing.metricCardinalityLimits == nil {
+			o.sanitizing.metricCardinalityLimits = map[string]int{}
+		}
+		o.sanitizing.metricCardinalityLimits[metricName] = limit
+	}
+}
+
+// WithMetricAttributesAllowList only exports the given attributes for the given metric. Other attributes are dropped.
+func WithMetricAttributesAllowList(metricName string, keys ...string) InitOpt {
+	return func(o *initDefaultOptions) {
+		o.sanitizing.allowedAttributes = addMetricAttributeKeys(o.sanitizing.allowedAttributes, metricName, keys)
+	}
+}
+
+// WithMetricAttributesDenyList never exports the given attributes for the given metric.
+func WithMetricAttributesDenyList(metricName string, keys ...string) InitOpt {
+	return func(o *initDefaultOptions) {
+		o.sanitizing.deniedAttributes = addMetricAttributeKeys(o.sanitizing.deniedAttributes, metricName, keys)
+	}
+}
+
+func addMetricAttributeKeys(lists map[string]map[attribute.Key]bool, metricName string, keys []string) map[string]map[attribute.Key]bool {
+	if lists == nil {
+		lists = map[string]map[attribute.Key]bool{}
+	}
+	if lists[metricName] == nil {
+		lists[metricName] = map[attribute.Key]bool{}
+	}
+	for _, key := range keys {
+		lists[metricName][attribute.Key(key)] = true
+	}
+	return lists
+}
+
 type sanitizingExporter struct {
 	exporter sdkmetric.Exporter
 	log      logrus.FieldLogger
+	options  sanitizingOptions
+	// cardinality and droppedAttributes are nil if the exporter has been built without newSanitizingExporter
+	cardinality       *cardinalityTracker
+	droppedAttributes *droppedAttributesCounter
 }
 
-func newSanitizingExporter(ctx context.Context, exporter sdkmetric.Exporter) sdkmetric.Exporter {
+func newSanitizingExporter(ctx context.Context, exporter sdkmetric.Exporter, options sanitizingOptions) sanitizingExporter {
 	return sanitizingExporter{
-		exporter: exporter,
-		log:      logger.Get(ctx),
+		exporter:          exporter,
+		log:               logger.Get(ctx),
+		options:           options,
+		cardinality:       newCardinalityTracker(),
+		droppedAttributes: &droppedAttributesCounter{},
 	}
 }
 
@@ -33,7 +131,7 @@ func (e sanitizingExporter) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric
 }
 
 func (e sanitizingExporter) Export(ctx context.Context, metrics *metricdata.ResourceMetrics) error {
-	e.sanitizeResourceMetrics(metrics)
+	e.sanitizeResourceMetrics(ctx, metrics)
 	return e.exporter.Export(ctx, metrics)
 }
 
@@ -45,13 +143,13 @@ func (e sanitizingExporter) Shutdown(ctx context.Context) error {
 	return e.exporter.Shutdown(ctx)
 }
 
-func (e sanitizingExporter) sanitizeResourceMetrics(metrics *metricdata.ResourceMetrics) {
+func (e sanitizingExporter) sanitizeResourceMetrics(ctx context.Context, metrics *metricdata.ResourceMetrics) {
 	if metrics == nil {
 		return
 	}
 
 	if metrics.Resource != nil {
-		attrs, changed := e.sanitizeAttributes("resource", "", metrics.Resource.Attributes())
+		attrs, changed := e.sanitizeAttributes(ctx, "resource", "", *metrics.Resource.Set())
 		if changed {
 			metrics.Resource = resource.NewWithAttributes(metrics.Resource.SchemaURL(), attrs...)
 		}
@@ -60,96 +158,281 @@ func (e sanitizingExporter) sanitizeResourceMetrics(metrics *metricdata.Resource
 	for scopeIdx := range metrics.ScopeMetrics {
 		scopeMetrics := &metrics.ScopeMetrics[scopeIdx]
 		for metricIdx := range scopeMetrics.Metrics {
-			e.sanitizeMetric(&scopeMetrics.Metrics[metricIdx])
+			e.sanitizeMetric(ctx, &scopeMetrics.Metrics[metricIdx])
 		}
 	}
 }
 
-func (e sanitizingExporter) sanitizeMetric(metric *metricdata.Metrics) {
+func (e sanitizingExporter) sanitizeMetric(ctx context.Context, metric *metricdata.Metrics) {
 	switch data := metric.Data.(type) {
 	case metricdata.Gauge[int64]:
-		sanitizeDataPoints(e, metric.Name, data.DataPoints)
+		data.DataPoints = sanitizeDataPoints(ctx, e, metric.Name, data.DataPoints, dataPointAttributes, mergeGaugeDataPoints)
 		metric.Data = data
 	case metricdata.Gauge[float64]:
-		sanitizeDataPoints(e, metric.Name, data.DataPoints)
+		data.DataPoints = sanitizeDataPoints(ctx, e, metric.Name, data.DataPoints, dataPointAttributes, mergeGaugeDataPoints)
 		metric.Data = data
 	case metricdata.Sum[int64]:
-		sanitizeDataPoints(e, metric.Name, data.DataPoints)
+		data.DataPoints = sanitizeDataPoints(ctx, e, metric.Name, data.DataPoints, dataPointAttributes, mergeSumDataPoints)
 		metric.Data = data
 	case metricdata.Sum[float64]:
-		sanitizeDataPoints(e, metric.Name, data.DataPoints)
+		data.DataPoints = sanitizeDataPoints(ctx, e, metric.Name, data.DataPoints, dataPointAttributes, mergeSumDataPoints)
 		metric.Data = data
 	case metricdata.Histogram[int64]:
-		sanitizeHistogramDataPoints(e, metric.Name, data.DataPoints)
+		data.DataPoints = sanitizeDataPoints(ctx, e, metric.Name, data.DataPoints, histogramDataPointAttributes, mergeHistogramDataPoints)
 		metric.Data = data
 	case metricdata.Histogram[float64]:
-		sanitizeHistogramDataPoints(e, metric.Name, data.DataPoints)
+		data.DataPoints = sanitizeDataPoints(ctx, e, metric.Name, data.DataPoints, histogramDataPointAttributes, mergeHistogramDataPoints)
 		metric.Data = data
 	case metricdata.ExponentialHistogram[int64]:
-		sanitizeExponentialHistogramDataPoints(e, metric.Name, data.DataPoints)
+		data.DataPoints = sanitizeDataPoints(ctx, e, metric.Name, data.DataPoints, exponentialHistogramDataPointAttributes[int64], mergeExponentialHistogramDataPoints)
 		metric.Data = data
 	case metricdata.ExponentialHistogram[float64]:
-		sanitizeExponentialHistogramDataPoints(e, metric.Name, data.DataPoints)
+		data.DataPoints = sanitizeDataPoints(ctx, e, metric.Name, data.DataPoints, exponentialHistogramDataPointAttributes[float64], mergeExponentialHistogramDataPoints)
 		metric.Data = data
 	case metricdata.Summary:
-		sanitizeSummaryDataPoints(e, metric.Name, data.DataPoints)
+		data.DataPoints = sanitizeDataPoints(ctx, e, metric.Name, data.DataPoints, summaryDataPointAttributes, mergeSummaryDataPoints)
 		metric.Data = data
 	}
 }
 
-func sanitizeDataPoints[N int64 | float64](exporter sanitizingExporter, metricName string, dataPoints []metricdata.DataPoint[N]) {
-	for idx := range dataPoints {
-		attrs, changed := exporter.sanitizeAttributes("datapoint", metricName, dataPoints[idx].Attributes.ToSlice())
+// sanitizeDataPoints sanitizes the attributes of each data point and applies the cardinality limit of the metric.
+// Data points ending up with the same attribute set are merged with the merge function. A data point which cannot be
+// merged, e.g. a histogram with different bucket boundaries, is dropped with a warning.
+func sanitizeDataPoints[DP any](
+	ctx context.Context, exporter sanitizingExporter, metricName string, dataPoints []DP,
+	attributes func(*DP) *attribute.Set, merge func(into *DP, from DP) bool,
+) []DP {
+	limit := exporter.options.cardinalityLimitFor(metricName)
+
+	sanitized := make([]DP, 0, len(dataPoints))
+	indexes := make(map[attribute.Distinct]int, len(dataPoints))
+	for _, dataPoint := range dataPoints {
+		attrs := attributes(&dataPoint)
+		sanitizedAttrs, changed := exporter.sanitizeAttributes(ctx, "datapoint", metricName, *attrs)
 		if changed {
-			dataPoints[idx].Attributes = attribute.NewSet(attrs...)
+			*attrs = attribute.NewSet(sanitizedAttrs...)
+		}
+
+		if limit > 0 && exporter.cardinality != nil && !exporter.cardinality.admit(metricName, *attrs, limit) {
+			if exporter.droppedAttributes.firstSeen("overflow", metricName, *attrs) {
+				exporter.recordDroppedAttributes(ctx, metricName, droppedReasonCardinalityLimit, attrs.Len())
+			}
+			*attrs = attribute.NewSet(OverflowAttributeKey.Bool(true))
+		}
+
+		idx, ok := indexes[attrs.Equivalent()]
+		if !ok {
+			indexes[attrs.Equivalent()] = len(sanitized)
+			sanitized = append(sanitized, dataPoint)
+			continue
+		}
+		if !merge(&sanitized[idx], dataPoint) {
+			exporter.log.WithFields(logrus.Fields{
+				"metric_name": metricName,
+				"attributes":  attrs.Encoded(attribute.DefaultEncoder()),
+			}).Warn("OpenTelemetry data point dropped, it cannot be merged with the data point having the same attributes")
 		}
 	}
+
+	return sanitized
 }
 
-func sanitizeHistogramDataPoints[N int64 | float64](exporter sanitizingExporter, metricName string, dataPoints []metricdata.HistogramDataPoint[N]) {
-	for idx := range dataPoints {
-		attrs, changed := exporter.sanitizeAttributes("datapoint", metricName, dataPoints[idx].Attributes.ToSlice())
-		if changed {
-			dataPoints[idx].Attributes = attribute.NewSet(attrs...)
-		}
+func dataPointAttributes[N int64 | float64](dataPoint *metricdata.DataPoint[N]) *attribute.Set {
+	return &dataPoint.Attributes
+}
+
+func histogramDataPointAttributes[N int64 | float64](dataPoint *metricdata.HistogramDataPoint[N]) *attribute.Set {
+	return &dataPoint.Attributes
+}
+
+func exponentialHistogramDataPointAttributes[N int64 | float64](dataPoint *metricdata.ExponentialHistogramDataPoint[N]) *attribute.Set {
+	return &dataPoint.Attributes
+}
+
+func summaryDataPointAttributes(dataPoint *metricdata.SummaryDataPoint) *attribute.Set {
+	return &dataPoint.Attributes
+}
+
+func mergeGaugeDataPoints[N int64 | float64](into *metricdata.DataPoint[N], from metricdata.DataPoint[N]) bool {
+	// A gauge reports the last value recorded
+	if from.Time.After(into.Time) {
+		into.Time = from.Time
+		into.Value = from.Value
 	}
+	return true
 }
 
-func sanitizeExponentialHistogramDataPoints[N int64 | float64](exporter sanitizingExporter, metricName string, dataPoints []metricdata.ExponentialHistogramDataPoint[N]) {
-	for idx := range dataPoints {
-		attrs, changed := exporter.sanitizeAttributes("datapoint", metricName, dataPoints[idx].Attributes.ToSlice())
-		if changed {
-			dataPoints[idx].Attributes = attribute.NewSet(attrs...)
-		}
+func mergeSumDataPoints[N int64 | float64](into *metricdata.DataPoint[N], from metricdata.DataPoint[N]) bool {
+	into.Value += from.Value
+	mergeTimes(&into.StartTime, &into.Time, from.StartTime, from.Time)
+	return true
+}
+
+// mergeHistogramDataPoints merges histograms having the same bucket boundaries
+func mergeHistogramDataPoints[N int64 | float64](into *metricdata.HistogramDataPoint[N], from metricdata.HistogramDataPoint[N]) bool {
+	if !slices.Equal(into.Bounds, from.Bounds) || len(into.BucketCounts) != len(from.BucketCounts) {
+		return false
 	}
+
+	into.Count += from.Count
+	into.Sum += from.Sum
+	for idx := range from.BucketCounts {
+		into.BucketCounts[idx] += from.BucketCounts[idx]
+	}
+	into.Min, into.Max = mergeExtrema(into.Min, into.Max, from.Min, from.Max)
+	mergeTimes(&into.StartTime, &into.Time, from.StartTime, from.Time)
+	return true
 }
 
-func sanitizeSummaryDataPoints(exporter sanitizingExporter, metricName string, dataPoints []metricdata.SummaryDataPoint) {
-	for idx := range dataPoints {
-		attrs, changed := exporter.sanitizeAttributes("datapoint", metricName, dataPoints[idx].Attributes.ToSlice())
-		if changed {
-			dataPoints[idx].Attributes = attribute.NewSet(attrs...)
-		}
+// mergeExponentialHistogramDataPoints merges exponential histograms having the same zero threshold. The buckets of the
+// histogram with the finest scale are downscaled to the coarsest scale.
+func mergeExponentialHistogramDataPoints[N int64 | float64](into *metricdata.ExponentialHistogramDataPoint[N], from metricdata.ExponentialHistogramDataPoint[N]) bool {
+	if into.ZeroThreshold != from.ZeroThreshold {
+		return false
+	}
+
+	scale := min(into.Scale, from.Scale)
+	into.PositiveBucket = addExponentialBuckets(
+		downscaleExponentialBucket(into.PositiveBucket, into.Scale-scale),
+		downscaleExponentialBucket(from.PositiveBucket, from.Scale-scale),
+	)
+	into.NegativeBucket = addExponentialBuckets(
+		downscaleExponentialBucket(into.NegativeBucket, into.Scale-scale),
+		downscaleExponentialBucket(from.NegativeBucket, from.Scale-scale),
+	)
+	into.Scale = scale
+	into.Count += from.Count
+	into.Sum += from.Sum
+	into.ZeroCount += from.ZeroCount
+	into.Min, into.Max = mergeExtrema(into.Min, into.Max, from.Min, from.Max)
+	mergeTimes(&into.StartTime, &into.Time, from.StartTime, from.Time)
+	return true
+}
+
+// downscaleExponentialBucket returns the bucket at a scale reduced by the given difference. At each scale reduction,
+// two consecutive buckets are merged into one.
+func downscaleExponentialBucket(bucket metricdata.ExponentialBucket, by int32) metricdata.ExponentialBucket {
+	if by == 0 || len(bucket.Counts) == 0 {
+		return bucket
+	}
+
+	offset := bucket.Offset >> by
+	last := (bucket.Offset + int32(len(bucket.Counts)) - 1) >> by
+	counts := make([]uint64, last-offset+1)
+	for idx, count := range bucket.Counts {
+		counts[(bucket.Offset+int32(idx))>>by-offset] += count
 	}
+	return metricdata.ExponentialBucket{Offset: offset, Counts: counts}
 }
 
-func (e sanitizingExporter) sanitizeAttributes(location, metricName string, attrs []attribute.KeyValue) ([]attribute.KeyValue, bool) {
-	sanitizedAttrs := make([]attribute.KeyValue, 0, len(attrs))
+// addExponentialBuckets returns the sum of two buckets of the same scale
+func addExponentialBuckets(a, b metricdata.ExponentialBucket) metricdata.ExponentialBucket {
+	if len(a.Counts) == 0 {
+		return b
+	}
+	if len(b.Counts) == 0 {
+		return a
+	}
+
+	offset := min(a.Offset, b.Offset)
+	end := max(a.Offset+int32(len(a.Counts)), b.Offset+int32(len(b.Counts)))
+	counts := make([]uint64, end-offset)
+	for idx, count := range a.Counts {
+		counts[a.Offset+int32(idx)-offset] += count
+	}
+	for idx, count := range b.Counts {
+		counts[b.Offset+int32(idx)-offset] += count
+	}
+	return metricdata.ExponentialBucket{Offset: offset, Counts: counts}
+}
+
+// mergeSummaryDataPoints merges the count and sum of the summaries. The quantiles cannot be computed from the quantiles
+// of each summary, hence they are removed.
+func mergeSummaryDataPoints(into *metricdata.SummaryDataPoint, from metricdata.SummaryDataPoint) bool {
+	into.Count += from.Count
+	into.Sum += from.Sum
+	into.QuantileValues = nil
+	mergeTimes(&into.StartTime, &into.Time, from.StartTime, from.Time)
+	return true
+}
+
+func mergeExtrema[N int64 | float64](intoMin, intoMax, fromMin, fromMax metricdata.Extrema[N]) (metricdata.Extrema[N], metricdata.Extrema[N]) {
+	fromMinValue, fromMinDefined := fromMin.Value()
+	intoMinValue, intoMinDefined := intoMin.Value()
+	if fromMinDefined && (!intoMinDefined || fromMinValue < intoMinValue) {
+		intoMin = fromMin
+	}
+	fromMaxValue, fromMaxDefined := fromMax.Value()
+	intoMaxValue, intoMaxDefined := intoMax.Value()
+	if fromMaxDefined && (!intoMaxDefined || fromMaxValue > intoMaxValue) {
+		intoMax = fromMax
+	}
+	return intoMin, intoMax
+}
+
+// mergeTimes widens the time range of a data point to the range of the data point merged into it
+func mergeTimes(intoStartTime, intoTime *time.Time, fromStartTime, fromTime time.Time) {
+	if fromStartTime.Before(*intoStartTime) {
+		*intoStartTime = fromStartTime
+	}
+	if fromTime.After(*intoTime) {
+		*intoTime = fromTime
+	}
+}
+
+// sanitizeAttributes returns the exported attributes of the attribute set. The dropped attributes are only logged and
+// counted the first time the attribute set is exported, as the cumulative data points are exported again at each
+// collection.
+func (e sanitizingExporter) sanitizeAttributes(ctx context.Context, location, metricName string, attrs attribute.Set) ([]attribute.KeyValue, bool) {
+	sanitizedAttrs := make([]attribute.KeyValue, 0, attrs.Len())
 	changed := false
+	firstSeen := false
 
-	for _, attr := range attrs {
-		if !isExportableAttribute(attr) {
-			changed = true
-			e.logDroppedAttribute(location, metricName, attr)
+	iter := attrs.Iter()
+	for iter.Next() {
+		attr := iter.Attribute()
+		reason := e.options.droppedReason(location, metricName, attr)
+		if reason == "" {
+			sanitizedAttrs = append(sanitizedAttrs, attr)
 			continue
 		}
 
-		sanitizedAttrs = append(sanitizedAttrs, attr)
+		if !changed {
+			changed = true
+			firstSeen = e.droppedAttributes.firstSeen(location, metricName, attrs)
+		}
+		if !firstSeen {
+			continue
+		}
+		if reason == droppedReasonEmpty {
+			e.logDroppedAttribute(location, metricName, attr)
+		}
+		e.recordDroppedAttributes(ctx, metricName, reason, 1)
 	}
 
 	return sanitizedAttrs, changed
 }
 
+// droppedReason returns the reason for which the attribute is not exported, or "" if it is exported. The allow and
+// deny lists only apply to the data points attributes.
+func (o sanitizingOptions) droppedReason(location, metricName string, attr attribute.KeyValue) string {
+	if !isExportableAttribute(attr) {
+		return droppedReasonEmpty
+	}
+	if location != "datapoint" {
+		return ""
+	}
+
+	if o.deniedAttributes[metricName][attr.Key] {
+		return droppedReasonDenied
+	}
+	allowed := o.allowedAttributes[metricName]
+	if allowed != nil && !allowed[attr.Key] {
+		return droppedReasonNotAllowed
+	}
+	return ""
+}
+
 func isExportableAttribute(attr attribute.KeyValue) bool {
 	if string(attr.Key) == "" {
 		return false
@@ -175,3 +458,110 @@ func (e sanitizingExporter) logDroppedAttribute(location, metricName string, att
 
 	e.log.WithFields(fields).Error("OpenTelemetry attribute with empty name or value removed")
 }
+
+func (e sanitizingExporter) recordDroppedAttributes(ctx context.Context, metricName, reason string, count int) {
+	if e.droppedAttributes == nil || count == 0 {
+		return
+	}
+
+	attrs := []attribute.KeyValue{attribute.String("reason", reason)}
+	if metricName != "" {
+		attrs = append(attrs, attribute.String("metric_name", metricName))
+	}
+	e.droppedAttributes.add(ctx, e.log, int64(count), attrs...)
+}
+
+// cardinalityTracker remembers the attribute sets exported for each metric. Once admitted, an attribute set is always
+// exported so that the series remain consistent across exports.
+type cardinalityTracker struct {
+	lock sync.Mutex
+	seen map[string]map[attribute.Distinct]struct{}
+}
+
+func newCardinalityTracker() *cardinalityTracker {
+	return &cardinalityTracker{
+		seen: map[string]map[attribute.Distinct]struct{}{},
+	}
+}
+
+// admit returns whether the attribute set can be exported for the given metric. One slot is kept for the overflow
+// series.
+func (t *cardinalityTracker) admit(metricName string, attrs attribute.Set, limit int) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	seen, ok := t.seen[metricName]
+	if !ok {
+		seen = map[attribute.Distinct]struct{}{}
+		t.seen[metricName] = seen
+	}
+
+	_, ok = seen[attrs.Equivalent()]
+	if ok {
+		return true
+	}
+	if len(seen) >= limit-1 {
+		return false
+	}
+	seen[attrs.Equivalent()] = struct{}{}
+	return true
+}
+
+// droppedAttributesCounter lazily creates the dropped attributes counter from the global MeterProvider, which is only
+// set once the exporter has been created. It also remembers the attribute sets whose dropped attributes have already
+// been counted.
+type droppedAttributesCounter struct {
+	once    sync.Once
+	counter metric.Int64Counter
+
+	lock sync.Mutex
+	seen map[droppedAttributesKey]struct{}
+}
+
+type droppedAttributesKey struct {
+	location   string
+	metricName string
+	attrs      attribute.Distinct
+}
+
+// firstSeen returns whether the attributes dropped from the attribute set have not been counted yet. It always returns
+// true on a nil counter.
+func (c *droppedAttributesCounter) firstSeen(location, metricName string, attrs attribute.Set) bool {
+	if c == nil {
+		return true
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.seen == nil {
+		c.seen = map[droppedAttributesKey]struct{}{}
+	}
+	key := droppedAttributesKey{location: location, metricName: metricName, attrs: attrs.Equivalent()}
+	_, ok := c.seen[key]
+	if ok {
+		return false
+	}
+	c.seen[key] = struct{}{}
+	return true
+}
+
+func (c *droppedAttributesCounter) add(ctx context.Context, log logrus.FieldLogger, count int64, attrs ...attribute.KeyValue) {
+	c.once.Do(func() {
+		counter, err := otelsdk.Meter(instrumentationName).Int64Counter(
+			DroppedAttributesMetricName,
+			metric.WithDescription("Number of metric attributes dropped before export"),
+			metric.WithUnit("{attribute}"),
+		)
+		if err != nil {
+			log.WithError(err).Error("Fail to create the OpenTelemetry dropped attributes counter")
+			return
+		}
+		c.counter = counter
+	})
+	if c.counter == nil {
+		return
+	}
+
+	c.counter.Add(ctx, count, metric.WithAttributes(attrs...))
+}
@@ -7,11 +7,14 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
+	otelsdk "go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.uber.org/mock/gomock"
 
+	"github.com/Scalingo/go-utils/logger"
 	"github.com/Scalingo/go-utils/otel/otelmock"
 )
 
@@ -84,3 +87,309 @@ func TestSanitizingExporter_ExportRemovesEmptyAttributes(t *testing.T) {
 	err := exporter.Export(t.Context(), metrics)
 	require.NoError(t, err)
 }
+
+func TestSanitizingExporter_ExportFiltersAttributes(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       sanitizingOptions
+		expectedAttrs []attribute.KeyValue
+	}{
+		{
+			name: "it should only keep the allowed attributes",
+			options: sanitizingOptions{
+				allowedAttributes: map[string]map[attribute.Key]bool{"test_counter": {"app": true}},
+			},
+			expectedAttrs: []attribute.KeyValue{attribute.String("app", "my-app")},
+		}, {
+			name: "it should drop the denied attributes",
+			options: sanitizingOptions{
+				deniedAttributes: map[string]map[attribute.Key]bool{"test_counter": {"user_id": true}},
+			},
+			expectedAttrs: []attribute.KeyValue{attribute.String("app", "my-app"), attribute.String("region", "osc-fr1")},
+		}, {
+			name: "it should not filter the attributes of other metrics",
+			options: sanitizingOptions{
+				deniedAttributes: map[string]map[attribute.Key]bool{"other_counter": {"user_id": true}},
+			},
+			expectedAttrs: []attribute.KeyValue{
+				attribute.String("app", "my-app"), attribute.String("region", "osc-fr1"), attribute.String("user_id", "42"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			exporter, exported := newTestSanitizingExporter(t, test.options)
+
+			err := exporter.Export(t.Context(), sumResourceMetrics(
+				metricdata.DataPoint[int64]{
+					Attributes: attribute.NewSet(
+						attribute.String("app", "my-app"), attribute.String("region", "osc-fr1"), attribute.String("user_id", "42"),
+					),
+					Value: 1,
+				},
+			))
+			require.NoError(t, err)
+
+			sum := (*exported).ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+			require.Len(t, sum.DataPoints, 1)
+			require.ElementsMatch(t, test.expectedAttrs, sum.DataPoints[0].Attributes.ToSlice())
+		})
+	}
+}
+
+func TestSanitizingExporter_ExportMergesDataPoints(t *testing.T) {
+	exporter, exported := newTestSanitizingExporter(t, sanitizingOptions{
+		deniedAttributes: map[string]map[attribute.Key]bool{"test_counter": {"user_id": true}},
+	})
+
+	err := exporter.Export(t.Context(), sumResourceMetrics(
+		metricdata.DataPoint[int64]{Attributes: attribute.NewSet(attribute.String("app", "a"), attribute.String("user_id", "1")), Value: 1},
+		metricdata.DataPoint[int64]{Attributes: attribute.NewSet(attribute.String("app", "a"), attribute.String("user_id", "2")), Value: 2},
+		metricdata.DataPoint[int64]{Attributes: attribute.NewSet(attribute.String("app", "b"), attribute.String("user_id", "3")), Value: 4},
+	))
+	require.NoError(t, err)
+
+	sum := (*exported).ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	require.Len(t, sum.DataPoints, 2)
+	require.Equal(t, attribute.NewSet(attribute.String("app", "a")), sum.DataPoints[0].Attributes)
+	require.Equal(t, int64(3), sum.DataPoints[0].Value)
+	require.Equal(t, attribute.NewSet(attribute.String("app", "b")), sum.DataPoints[1].Attributes)
+	require.Equal(t, int64(4), sum.DataPoints[1].Value)
+}
+
+func TestSanitizingExporter_ExportCardinalityLimit(t *testing.T) {
+	exporter, exported := newTestSanitizingExporter(t, sanitizingOptions{
+		cardinalityLimit:        100,
+		metricCardinalityLimits: map[string]int{"test_counter": 3},
+	})
+	overflowAttrs := attribute.NewSet(OverflowAttributeKey.Bool(true))
+
+	err := exporter.Export(t.Context(), sumResourceMetrics(
+		metricdata.DataPoint[int64]{Attributes: attribute.NewSet(attribute.String("app", "a")), Value: 1},
+		metricdata.DataPoint[int64]{Attributes: attribute.NewSet(attribute.String("app", "b")), Value: 2},
+		metricdata.DataPoint[int64]{Attributes: attribute.NewSet(attribute.String("app", "c")), Value: 4},
+		metricdata.DataPoint[int64]{Attributes: attribute.NewSet(attribute.String("app", "d")), Value: 8},
+	))
+	require.NoError(t, err)
+
+	sum := (*exported).ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	require.Len(t, sum.DataPoints, 3)
+	require.Equal(t, attribute.NewSet(attribute.String("app", "a")), sum.DataPoints[0].Attributes)
+	require.Equal(t, attribute.NewSet(attribute.String("app", "b")), sum.DataPoints[1].Attributes)
+	require.Equal(t, overflowAttrs, sum.DataPoints[2].Attributes)
+	require.Equal(t, int64(12), sum.DataPoints[2].Value)
+
+	// The attribute sets admitted during the first export are still exported afterwards
+	err = exporter.Export(t.Context(), sumResourceMetrics(
+		metricdata.DataPoint[int64]{Attributes: attribute.NewSet(attribute.String("app", "e")), Value: 16},
+		metricdata.DataPoint[int64]{Attributes: attribute.NewSet(attribute.String("app", "b")), Value: 32},
+	))
+	require.NoError(t, err)
+
+	sum = (*exported).ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	require.Len(t, sum.DataPoints, 2)
+	require.Equal(t, overflowAttrs, sum.DataPoints[0].Attributes)
+	require.Equal(t, attribute.NewSet(attribute.String("app", "b")), sum.DataPoints[1].Attributes)
+}
+
+func TestSanitizingExporter_ExportMergesHistogramDataPoints(t *testing.T) {
+	exporter, exported := newTestSanitizingExporter(t, sanitizingOptions{cardinalityLimit: 1})
+
+	err := exporter.Export(t.Context(), &metricdata.ResourceMetrics{
+		ScopeMetrics: []metricdata.ScopeMetrics{{
+			Metrics: []metricdata.Metrics{{
+				Name: "test_histogram",
+				Data: metricdata.Histogram[float64]{
+					DataPoints: []metricdata.HistogramDataPoint[float64]{
+						{
+							Attributes: attribute.NewSet(attribute.String("app", "a")),
+							Bounds:     []float64{1}, BucketCounts: []uint64{1, 0},
+							Count: 1, Sum: 0.5, Min: metricdata.NewExtrema(0.5), Max: metricdata.NewExtrema(0.5),
+						}, {
+							Attributes: attribute.NewSet(attribute.String("app", "b")),
+							Bounds:     []float64{1}, BucketCounts: []uint64{0, 2},
+							Count: 2, Sum: 5, Min: metricdata.NewExtrema(2.0), Max: metricdata.NewExtrema(3.0),
+						},
+					},
+				},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+
+	histogram := (*exported).ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+	require.Len(t, histogram.DataPoints, 1)
+	dataPoint := histogram.DataPoints[0]
+	require.Equal(t, attribute.NewSet(OverflowAttributeKey.Bool(true)), dataPoint.Attributes)
+	require.Equal(t, uint64(3), dataPoint.Count)
+	require.InDelta(t, 5.5, dataPoint.Sum, 0.001)
+	require.Equal(t, []uint64{1, 2}, dataPoint.BucketCounts)
+	require.Equal(t, metricdata.NewExtrema(0.5), dataPoint.Min)
+	require.Equal(t, metricdata.NewExtrema(3.0), dataPoint.Max)
+}
+
+func TestSanitizingExporter_ExportDropsHistogramDataPointsWithDifferentBounds(t *testing.T) {
+	exporter, exported := newTestSanitizingExporter(t, sanitizingOptions{cardinalityLimit: 1})
+
+	err := exporter.Export(t.Context(), &metricdata.ResourceMetrics{
+		ScopeMetrics: []metricdata.ScopeMetrics{{
+			Metrics: []metricdata.Metrics{{
+				Name: "test_histogram",
+				Data: metricdata.Histogram[float64]{
+					DataPoints: []metricdata.HistogramDataPoint[float64]{
+						{
+							Attributes: attribute.NewSet(attribute.String("app", "a")),
+							Bounds:     []float64{1}, BucketCounts: []uint64{1, 0}, Count: 1, Sum: 0.5,
+						}, {
+							Attributes: attribute.NewSet(attribute.String("app", "b")),
+							Bounds:     []float64{10}, BucketCounts: []uint64{0, 2}, Count: 2, Sum: 30,
+						},
+					},
+				},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+
+	histogram := (*exported).ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+	require.Len(t, histogram.DataPoints, 1)
+	require.Equal(t, uint64(1), histogram.DataPoints[0].Count)
+	require.Equal(t, []uint64{1, 0}, histogram.DataPoints[0].BucketCounts)
+}
+
+func TestSanitizingExporter_ExportMergesExponentialHistogramDataPoints(t *testing.T) {
+	exporter, exported := newTestSanitizingExporter(t, sanitizingOptions{cardinalityLimit: 1})
+
+	err := exporter.Export(t.Context(), &metricdata.ResourceMetrics{
+		ScopeMetrics: []metricdata.ScopeMetrics{{
+			Metrics: []metricdata.Metrics{{
+				Name: "test_exponential_histogram",
+				Data: metricdata.ExponentialHistogram[float64]{
+					DataPoints: []metricdata.ExponentialHistogramDataPoint[float64]{
+						{
+							Attributes: attribute.NewSet(attribute.String("app", "a")),
+							Count:      3, Sum: 6, Scale: 1, ZeroCount: 1,
+							PositiveBucket: metricdata.ExponentialBucket{Offset: 1, Counts: []uint64{1, 1}},
+						}, {
+							Attributes: attribute.NewSet(attribute.String("app", "b")),
+							Count:      2, Sum: 10, Scale: 0,
+							PositiveBucket: metricdata.ExponentialBucket{Offset: 2, Counts: []uint64{2}},
+						},
+					},
+				},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+
+	histogram := (*exported).ScopeMetrics[0].Metrics[0].Data.(metricdata.ExponentialHistogram[float64])
+	require.Len(t, histogram.DataPoints, 1)
+	dataPoint := histogram.DataPoints[0]
+	require.Equal(t, int32(0), dataPoint.Scale)
+	require.Equal(t, uint64(5), dataPoint.Count)
+	require.InDelta(t, 16, dataPoint.Sum, 0.001)
+	require.Equal(t, uint64(1), dataPoint.ZeroCount)
+	// The buckets 1 and 2 of scale 1 are the buckets 0 and 1 of scale 0
+	require.Equal(t, metricdata.ExponentialBucket{Offset: 0, Counts: []uint64{1, 1, 2}}, dataPoint.PositiveBucket)
+}
+
+func TestSanitizingExporter_ExportMergesSummaryDataPoints(t *testing.T) {
+	exporter, exported := newTestSanitizingExporter(t, sanitizingOptions{cardinalityLimit: 1})
+
+	err := exporter.Export(t.Context(), &metricdata.ResourceMetrics{
+		ScopeMetrics: []metricdata.ScopeMetrics{{
+			Metrics: []metricdata.Metrics{{
+				Name: "test_summary",
+				Data: metricdata.Summary{
+					DataPoints: []metricdata.SummaryDataPoint{
+						{
+							Attributes: attribute.NewSet(attribute.String("app", "a")), Count: 1, Sum: 2,
+							QuantileValues: []metricdata.QuantileValue{{Quantile: 0.5, Value: 2}},
+						}, {
+							Attributes: attribute.NewSet(attribute.String("app", "b")), Count: 3, Sum: 4,
+							QuantileValues: []metricdata.QuantileValue{{Quantile: 0.5, Value: 1}},
+						},
+					},
+				},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+
+	summary := (*exported).ScopeMetrics[0].Metrics[0].Data.(metricdata.Summary)
+	require.Len(t, summary.DataPoints, 1)
+	require.Equal(t, uint64(4), summary.DataPoints[0].Count)
+	require.InDelta(t, 6, summary.DataPoints[0].Sum, 0.001)
+	require.Empty(t, summary.DataPoints[0].QuantileValues)
+}
+
+func TestSanitizingExporter_ExportRecordsDroppedAttributes(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	initialMeterProvider := otelsdk.GetMeterProvider()
+	otelsdk.SetMeterProvider(meterProvider)
+	t.Cleanup(func() {
+		otelsdk.SetMeterProvider(initialMeterProvider)
+	})
+
+	exporter, _ := newTestSanitizingExporter(t, sanitizingOptions{
+		deniedAttributes: map[string]map[attribute.Key]bool{"test_counter": {"user_id": true}},
+	})
+
+	// The cumulative data points are exported again at each collection, their dropped attributes are only counted once
+	for range 2 {
+		err := exporter.Export(t.Context(), sumResourceMetrics(
+			metricdata.DataPoint[int64]{Attributes: attribute.NewSet(attribute.String("user_id", "1"), attribute.String("app", "")), Value: 1},
+			metricdata.DataPoint[int64]{Attributes: attribute.NewSet(attribute.String("user_id", "2")), Value: 1},
+		))
+		require.NoError(t, err)
+	}
+
+	var collected metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &collected))
+	require.Len(t, collected.ScopeMetrics, 1)
+	require.Equal(t, DroppedAttributesMetricName, collected.ScopeMetrics[0].Metrics[0].Name)
+
+	values := map[string]int64{}
+	for _, dataPoint := range collected.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64]).DataPoints {
+		reason, _ := dataPoint.Attributes.Value("reason")
+		values[reason.AsString()] = dataPoint.Value
+	}
+	require.Equal(t, map[string]int64{"denied": 2, "empty": 1}, values)
+}
+
+func newTestSanitizingExporter(t *testing.T, options sanitizingOptions) (sdkmetric.Exporter, **metricdata.ResourceMetrics) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	baseExporter := otelmock.NewMockExporter(ctrl)
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	exported := new(*metricdata.ResourceMetrics)
+	baseExporter.EXPECT().Export(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, metrics *metricdata.ResourceMetrics) error {
+			*exported = metrics
+			return nil
+		},
+	).AnyTimes()
+
+	exporter := newSanitizingExporter(logger.ToCtx(t.Context(), log), baseExporter, options)
+	return exporter, exported
+}
+
+func sumResourceMetrics(dataPoints ...metricdata.DataPoint[int64]) *metricdata.ResourceMetrics {
+	return &metricdata.ResourceMetrics{
+		ScopeMetrics: []metricdata.ScopeMetrics{{
+			Metrics: []metricdata.Metrics{{
+				Name: "test_counter",
+				Data: metricdata.Sum[int64]{
+					Temporality: metricdata.CumulativeTemporality,
+					IsMonotonic: true,
+					DataPoints:  dataPoints,
+				},
+			}},
+		}},
+	}
+}
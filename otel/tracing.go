@@ -0,0 +1,168 @@
+package otel
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"strconv"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/Scalingo/go-utils/errors/v3"
+)
+
+// Values of OTEL_TRACES_SAMPLER
+// https://opentelemetry.io/docs/specs/otel/configuration/sdk-environment-variables/#general-sdk-configuration
+const (
+	samplerAlwaysOn                = "always_on"
+	samplerAlwaysOff               = "always_off"
+	samplerTraceIDRatio            = "traceidratio"
+	samplerParentBasedAlwaysOn     = "parentbased_always_on"
+	samplerParentBasedAlwaysOff    = "parentbased_always_off"
+	samplerParentBasedTraceIDRatio = "parentbased_traceidratio"
+)
+
+// tracesExporterNone is the value of OTEL_TRACES_EXPORTER disabling the export of spans
+const tracesExporterNone = "none"
+
+// newTracerProvider creates a TracerProvider exporting spans in batch with the exporter configured in the environment.
+// It returns a nil TracerProvider if traces export is disabled or if the exporter does not support spans.
+func newTracerProvider(ctx context.Context, cfg *Config, res *resource.Resource, exporterType string, output *exporterOutput) (*sdktrace.TracerProvider, error) {
+	if cfg.TracesExporter == tracesExporterNone || exporterType == ExporterTypePrometheus {
+		return nil, nil
+	}
+
+	sampler, err := newSampler(ctx, cfg)
+	if err != nil {
+		return nil, errors.Wrap(ctx, err, "create traces sampler")
+	}
+
+	tracesExporter, err := newTracesExporter(ctx, cfg, exporterType, output)
+	if err != nil {
+		return nil, errors.Wrap(ctx, err, "create traces exporter")
+	}
+
+	// The batch span processor is configured with the OTEL_BSP_* environment variables
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(tracesExporter),
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithResource(res),
+	), nil
+}
+
+// newTextMapPropagator returns the propagator of the W3C Trace Context and Baggage headers
+func newTextMapPropagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+func newSampler(ctx context.Context, cfg *Config) (sdktrace.Sampler, error) {
+	ratio := 1.0
+	if cfg.TracesSamplerArg != "" {
+		var err error
+		ratio, err = strconv.ParseFloat(cfg.TracesSamplerArg, 64)
+		if err != nil {
+			return nil, errors.Wrap(ctx, err, "parse traces sampler argument")
+		}
+		if ratio < 0 || ratio > 1 {
+			return nil, errors.Newf(ctx, "traces sampler argument must be between 0 and 1, got %v", ratio)
+		}
+	}
+
+	switch cfg.TracesSampler {
+	case samplerAlwaysOn:
+		return sdktrace.AlwaysSample(), nil
+	case samplerAlwaysOff:
+		return sdktrace.NeverSample(), nil
+	case samplerTraceIDRatio:
+		return sdktrace.TraceIDRatioBased(ratio), nil
+	case samplerParentBasedAlwaysOn:
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case samplerParentBasedAlwaysOff:
+		return sdktrace.ParentBased(sdktrace.NeverSample()), nil
+	case samplerParentBasedTraceIDRatio:
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio)), nil
+	default:
+		return nil, errors.Newf(ctx, "invalid traces sampler '%s'", cfg.TracesSampler)
+	}
+}
+
+func newTracesExporter(ctx context.Context, cfg *Config, exporterType string, output *exporterOutput) (sdktrace.SpanExporter, error) {
+	enforceTLSByDefault := isTLSEnforced()
+
+	var tlsConfig *tls.Config
+	var err error
+	if enforceTLSByDefault && isOTLPExporterType(exporterType) {
+		tlsConfig, err = setTLSConfig(ctx, cfg)
+		if err != nil {
+			return nil, errors.Wrap(ctx, err, "set TLS configuration")
+		}
+	}
+
+	switch exporterType {
+	case ExporterTypeStdout:
+		opts := []stdouttrace.Option{stdouttrace.WithWriter(output)}
+		if cfg.DebugPrettyPrint {
+			opts = append(opts, stdouttrace.WithPrettyPrint())
+		}
+		exporter, err := stdouttrace.New(opts...)
+		if err != nil {
+			return nil, errors.Wrap(ctx, err, "create stdout exporter")
+		}
+		return exporter, nil
+	case ExporterTypeFile:
+		// Without pretty print, each span is written as a single JSON line
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(output))
+		if err != nil {
+			return nil, errors.Wrap(ctx, err, "create file exporter")
+		}
+		return exporter, nil
+	case ExporterTypeHTTP:
+		if enforceTLSByDefault {
+			exporter, err := otlptracehttp.New(
+				ctx, otlptracehttp.WithTLSClientConfig(tlsConfig),
+			)
+			if err != nil {
+				return nil, errors.Wrap(ctx, err, "create OTLP HTTPs exporter")
+			}
+			return exporter, nil
+		}
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, errors.Wrap(ctx, err, "create OTLP HTTP exporter")
+		}
+		return exporter, nil
+	case ExporterTypeGRPC:
+		if enforceTLSByDefault {
+			exporter, err := otlptracegrpc.New(
+				ctx, otlptracegrpc.WithDialOption(
+					grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+				),
+			)
+			if err != nil {
+				return nil, errors.Wrap(ctx, err, "create OTLP gRPC (TLS) exporter")
+			}
+			return exporter, nil
+		}
+		exporter, err := otlptracegrpc.New(ctx)
+		if err != nil {
+			return nil, errors.Wrap(ctx, err, "create OTLP gRPC exporter")
+		}
+		return exporter, nil
+	default:
+		return nil, errors.New(ctx, "invalid exporter type")
+	}
+}
+
+// isTLSEnforced returns whether the exporters must use TLS. TLS is enforced for production and staging environments.
+// In development and test environments, TLS is not enforced.
+func isTLSEnforced() bool {
+	environment := os.Getenv("GO_ENV")
+	return environment != "development" && environment != "test"
+}
@@ -0,0 +1,133 @@
+package otel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otelsdk "go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestNewSampler(t *testing.T) {
+	tests := []struct {
+		name              string
+		sampler           string
+		samplerArg        string
+		expectDescription string
+		expectErr         string
+	}{
+		{
+			name:              "always on",
+			sampler:           "always_on",
+			expectDescription: "AlwaysOnSampler",
+		}, {
+			name:              "always off",
+			sampler:           "always_off",
+			expectDescription: "AlwaysOffSampler",
+		}, {
+			name:              "trace ID ratio",
+			sampler:           "traceidratio",
+			samplerArg:        "0.25",
+			expectDescription: "TraceIDRatioBased{0.25}",
+		}, {
+			name:              "trace ID ratio without argument samples everything",
+			sampler:           "traceidratio",
+			expectDescription: "TraceIDRatioBased{1}",
+		}, {
+			name:              "parent based always on",
+			sampler:           "parentbased_always_on",
+			expectDescription: "ParentBased{root:AlwaysOnSampler,",
+		}, {
+			name:              "parent based trace ID ratio",
+			sampler:           "parentbased_traceidratio",
+			samplerArg:        "0.1",
+			expectDescription: "ParentBased{root:TraceIDRatioBased{0.1},",
+		}, {
+			name:      "invalid sampler",
+			sampler:   "unknown",
+			expectErr: "invalid traces sampler 'unknown'",
+		}, {
+			name:       "invalid ratio",
+			sampler:    "traceidratio",
+			samplerArg: "abc",
+			expectErr:  "parse traces sampler argument",
+		}, {
+			name:       "out of range ratio",
+			sampler:    "traceidratio",
+			samplerArg: "2",
+			expectErr:  "must be between 0 and 1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := t.Context()
+
+			sampler, err := newSampler(ctx, &Config{
+				TracesSampler:    test.sampler,
+				TracesSamplerArg: test.samplerArg,
+			})
+			if test.expectErr != "" {
+				require.ErrorContains(t, err, test.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, sampler.Description(), test.expectDescription)
+		})
+	}
+}
+
+func TestInit_TracerProvider(t *testing.T) {
+	minimalValidEnv := map[string]string{
+		"GO_ENV":                             "test",
+		"OTEL_SERVICE_NAME":                  "test",
+		"OTEL_EXPORTER_OTLP_ENDPOINT":        "http://localhost:4317",
+		"OTEL_EXPORTER_OTLP_METRICS_TIMEOUT": "1",
+		"OTEL_EXPORTER_OTLP_TRACES_TIMEOUT":  "1",
+	}
+
+	t.Run("it should set a SDK tracer provider", func(t *testing.T) {
+		for k, v := range minimalValidEnv {
+			t.Setenv(k, v)
+		}
+		t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+
+		shutdown := Init(t.Context())
+		t.Cleanup(func() {
+			require.NoError(t, shutdown())
+		})
+
+		assert.IsType(t, &sdktrace.TracerProvider{}, otelsdk.GetTracerProvider())
+		assert.ElementsMatch(t, []string{"traceparent", "tracestate", "baggage"}, otelsdk.GetTextMapPropagator().Fields())
+	})
+
+	t.Run("it should not replace the tracer provider if traces are disabled", func(t *testing.T) {
+		for k, v := range minimalValidEnv {
+			t.Setenv(k, v)
+		}
+		t.Setenv("OTEL_TRACES_EXPORTER", "none")
+
+		initialTracerProvider := otelsdk.GetTracerProvider()
+		shutdown := Init(t.Context())
+		t.Cleanup(func() {
+			require.NoError(t, shutdown())
+		})
+
+		assert.Same(t, initialTracerProvider, otelsdk.GetTracerProvider())
+	})
+
+	t.Run("it should skip the initialization if the sampler is invalid", func(t *testing.T) {
+		for k, v := range minimalValidEnv {
+			t.Setenv(k, v)
+		}
+		t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+		t.Setenv("OTEL_TRACES_SAMPLER", "invalid")
+
+		initialMeterProvider := otelsdk.GetMeterProvider()
+		shutdown := Init(t.Context())
+		require.NoError(t, shutdown())
+
+		assert.Same(t, initialMeterProvider, otelsdk.GetMeterProvider())
+	})
+}
@@ -20,6 +20,11 @@ func (err ObjectNotFound) Error() string {
 	return fmt.Sprintf("object %v not found", err.Path)
 }
 
+// NotFound lets errors.KindOf of github.com/Scalingo/go-utils/errors recognize the error as errors.KindNotFound
+func (err ObjectNotFound) NotFound() bool {
+	return true
+}
+
 const (
 	GetMethod    BackendMethod = "Get"
 	UploadMethod BackendMethod = "Upload"